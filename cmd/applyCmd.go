@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+	"github.com/CDavidSV/go-dbcompare/internal/config"
+	"github.com/CDavidSV/go-dbcompare/internal/helpers"
+	"github.com/CDavidSV/go-dbcompare/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Applies a SQL script to the second database",
+	Long:  "Executes a SQL script against the second database (target) inside a transaction, reports every failing statement and checks whether the target schema matches the first database (source) afterwards.",
+	Run: func(cmd *cobra.Command, args []string) {
+		configFilePath, _ := cmd.Flags().GetString("config")
+		scriptPath, _ := cmd.Flags().GetString("script")
+		dsn1, _ := cmd.Flags().GetString("dsn1")
+		dsn2, _ := cmd.Flags().GetString("dsn2")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		script, err := os.ReadFile(scriptPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error reading script:"), err)
+			os.Exit(1)
+		}
+
+		statements := internal.SplitStatements(string(script))
+		if len(statements) == 0 {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error: the script does not contain any statements"))
+			os.Exit(1)
+		}
+
+		conf, dsn1, dsn2, err := helpers.LoadConnections(configFilePath, dsn1, dsn2)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error reading configuration file:"), err)
+			os.Exit(1)
+		}
+
+		DB1, err := helpers.ConnectWithSpinner(conf.DB1.Name, dsn1)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), conf.DB1.Name, err)
+			os.Exit(1)
+		}
+		defer DB1.Close()
+
+		DB2, err := helpers.ConnectWithSpinner(conf.DB2.Name, dsn2)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), conf.DB2.Name, err)
+			os.Exit(1)
+		}
+		defer DB2.Close()
+		fmt.Fprintln(os.Stderr)
+
+		tx, err := DB2.BeginTx(context.Background(), nil)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error starting transaction:"), err)
+			os.Exit(1)
+		}
+		// Rolling back after a commit is a no-op
+		defer tx.Rollback()
+
+		results, err := internal.ExecuteScript(tx, statements)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error executing script:"), err)
+			os.Exit(1)
+		}
+
+		skipped := internal.Skipped(results)
+		for _, result := range skipped {
+			fmt.Fprintln(os.Stderr, config.InfoStyle.Render(fmt.Sprintf("Skipped transaction control statement %s", result.Summary())))
+		}
+
+		failed := internal.Failed(results)
+		for _, result := range failed {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render(fmt.Sprintf("✘ Statement %s", result.Summary())))
+			fmt.Fprintf(os.Stderr, "  %s\n", result.Error)
+		}
+		fmt.Fprintf(os.Stderr, "%d statements executed, %d skipped, %d failed\n\n", len(results)-len(skipped), len(skipped), len(failed))
+
+		// Check the schema as seen from inside the transaction
+		DB1Tables, err := internal.GetDBTableData(DB1)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error reading schema:"), err)
+			os.Exit(1)
+		}
+
+		DB2Tables, err := internal.GetDBTableData(tx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error reading schema:"), err)
+			os.Exit(1)
+		}
+
+		remaining, _ := internal.CompareTables(DB1Tables, DB2Tables, internal.CompareOptions{})
+		if remaining.HasSchemaDifferences() {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render(fmt.Sprintf("Schema differences remaining: %d column differences, %d tables missing in %s, %d tables missing in %s",
+				len(remaining.ColumnChanges()), len(remaining.MissingTablesInDB2), conf.DB2.Name, len(remaining.MissingTablesInDB1), conf.DB1.Name)))
+		} else {
+			fmt.Fprintln(os.Stderr, config.SuccessStyle.Render(fmt.Sprintf("✔ The schema of %s would match %s", conf.DB2.Name, conf.DB1.Name)))
+		}
+
+		if dryRun {
+			tx.Rollback()
+			fmt.Fprintln(os.Stderr, config.InfoStyle.Render("Dry run: all changes were rolled back"))
+			return
+		}
+
+		if len(failed) > 0 {
+			tx.Rollback()
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Changes were rolled back because some statements failed"))
+			os.Exit(1)
+		}
+
+		var output ui.TextInputValue
+		p := tea.NewProgram(ui.InitialTextInputModel(ui.TextInputOptions{
+			Label:       fmt.Sprintf("Commit changes to %s? (yes/no)", conf.DB2.Name),
+			Placeholder: "no",
+			Required:    true,
+			ValidationFunction: func(value string) error {
+				value = strings.ToLower(value)
+				if value != "yes" && value != "no" {
+					return fmt.Errorf("answer must be yes or no")
+				}
+
+				return nil
+			},
+		}, &output))
+		if _, err := p.Run(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintln(os.Stderr)
+
+		if strings.ToLower(output.Value) != "yes" {
+			tx.Rollback()
+			fmt.Fprintln(os.Stderr, config.InfoStyle.Render("Changes were rolled back"))
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error committing changes:"), err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintln(os.Stderr, config.SuccessStyle.Render("✔ Changes committed"))
+	},
+}
+
+func init() {
+	applyCmd.Flags().StringP("config", "c", "./db-compare-config.json", "path for the configuration file")
+	applyCmd.Flags().StringP("script", "s", "", "path of the SQL script to apply")
+	applyCmd.Flags().String("dsn1", "", "connection string for the source database")
+	applyCmd.Flags().String("dsn2", "", "connection string for the target database")
+	applyCmd.Flags().Bool("dry-run", false, "execute the script inside a transaction that is always rolled back")
+
+	applyCmd.MarkFlagRequired("script")
+}
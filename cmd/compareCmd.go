@@ -2,7 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"math/rand/v2"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/CDavidSV/go-dbcompare/internal"
@@ -21,90 +24,160 @@ var compareCmd = &cobra.Command{
 		configFilePath, _ := cmd.Flags().GetString("config")
 		outputPath, _ := cmd.Flags().GetString("output")
 		name, _ := cmd.Flags().GetString("name")
+		format, _ := cmd.Flags().GetString("format")
+		maxRows, _ := cmd.Flags().GetInt("max-rows")
 		dsn1, _ := cmd.Flags().GetString("dsn1")
 		dsn2, _ := cmd.Flags().GetString("dsn2")
+		privileges, _ := cmd.Flags().GetBool("privileges")
+		strictPartitions, _ := cmd.Flags().GetBool("strict-partitions")
+		strictColumnOrder, _ := cmd.Flags().GetBool("strict-column-order")
+		comments, _ := cmd.Flags().GetBool("comments")
+		settings, _ := cmd.Flags().GetBool("settings")
+		ignoredSettings, _ := cmd.Flags().GetStringArray("ignore-setting")
+		stats, _ := cmd.Flags().GetBool("stats")
+		exactCounts, _ := cmd.Flags().GetBool("exact-counts")
+		statsThreshold, _ := cmd.Flags().GetFloat64("stats-threshold")
+		profile, _ := cmd.Flags().GetBool("profile")
+		profileTolerance, _ := cmd.Flags().GetFloat64("profile-tolerance")
+		profileTop, _ := cmd.Flags().GetInt("profile-top")
+		data, _ := cmd.Flags().GetBool("data")
+		dataSample, _ := cmd.Flags().GetString("data-sample")
+		dataSeed, _ := cmd.Flags().GetInt64("data-seed")
+		dataMaxDiffs, _ := cmd.Flags().GetInt("data-max-diffs")
+
+		// Print the differences when running in a terminal and no output path was given
+		printText := !cmd.Flags().Changed("output") && helpers.IsTerminal(os.Stdout)
+		if format == "" {
+			format = "excel"
+			if printText {
+				format = "text"
+			}
+		}
+
+		if !slices.Contains(helpers.OutputFormats, format) {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render(fmt.Sprintf("Error: invalid output format %s, expected one of: %s", format, strings.Join(helpers.OutputFormats, ", "))))
+			os.Exit(1)
+		}
 
-		db1Name := "DB1"
-		db2Name := "DB2"
-		if dsn1 == "" || dsn2 == "" {
-			conf, err := helpers.LoadConfigurationFile(configFilePath)
+		var samplePercent float64
+		var sampleRows int64
+		if dataSample != "" {
+			var err error
+			samplePercent, sampleRows, err = internal.ParseDataSample(dataSample)
 			if err != nil {
-				fmt.Println(config.ErrorStyle.Render("Error reading configuration file:"), err)
+				fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error:"), err)
 				os.Exit(1)
 			}
 
-			db1Name = conf.DB1.Name
-			db2Name = conf.DB2.Name
-
-			dsn1 = helpers.GetDataSourceName("postgres", conf.DB1.Username, conf.DB1.Password, conf.DB1.HostName, conf.DB1.Database, conf.DB1.Port, conf.DB1.Params)
-			dsn2 = helpers.GetDataSourceName("postgres", conf.DB2.Username, conf.DB2.Password, conf.DB2.HostName, conf.DB2.Database, conf.DB2.Port, conf.DB2.Params)
+			data = true
 		}
 
-		helpers.SaveCursorPosition()
+		if !cmd.Flags().Changed("data-seed") {
+			dataSeed = rand.Int64N(1 << 31)
+		}
 
-		s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-		s.Suffix = fmt.Sprintf(config.InfoStyle.Render(" Connecting to %s"), db1Name)
-		s.Start()
+		conf, dsn1, dsn2, err := helpers.LoadConnections(configFilePath, dsn1, dsn2)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error reading configuration file:"), err)
+			os.Exit(1)
+		}
+		db1Name := conf.DB1.Name
+		db2Name := conf.DB2.Name
 
 		// Connect to databases
-		DB1, err := helpers.ConnectDB("postgres", dsn1)
-		s.Stop()
+		DB1, err := helpers.ConnectWithSpinner(db1Name, dsn1)
 		if err != nil {
-			helpers.ClearLine()
-			fmt.Printf(config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), db1Name, err)
+			fmt.Fprintf(os.Stderr, config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), db1Name, err)
 			os.Exit(1)
 		}
 		defer DB1.Close()
 
-		helpers.ClearLine()
-		fmt.Printf(config.SuccessStyle.Render("Connected to %s\n"), db1Name)
-		helpers.SaveCursorPosition()
-
-		s.Suffix = fmt.Sprintf(config.InfoStyle.Render(" Connecting to %s"), db2Name)
-		s.Start()
-
-		DB2, err := helpers.ConnectDB("postgres", dsn2)
-		s.Stop()
+		DB2, err := helpers.ConnectWithSpinner(db2Name, dsn2)
 		if err != nil {
-			helpers.ClearLine()
-			fmt.Printf(config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), db2Name, err)
+			fmt.Fprintf(os.Stderr, config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), db2Name, err)
 			os.Exit(1)
 		}
 		defer DB2.Close()
 
-		helpers.ClearLine()
-		fmt.Printf(config.SuccessStyle.Render("Connected to %s\n\n"), db2Name)
+		// Status messages go to stderr so the text result can be piped
+		fmt.Fprintln(os.Stderr)
 		helpers.SaveCursorPosition()
 
+		s := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriterFile(os.Stderr))
 		s.Suffix = config.InfoStyle.Render(" Running comparison")
 		s.Start()
 
-		result, err := internal.CompareDatabase(DB1, DB2)
+		result, err := internal.CompareDatabase(DB1, DB2, internal.CompareOptions{
+			Privileges:            privileges,
+			StrictPartitionBounds: strictPartitions,
+			StrictColumnOrder:     strictColumnOrder,
+			Comments:              comments,
+			Settings:              settings,
+			IgnoredSettings:       slices.Concat(internal.DefaultIgnoredSettings, ignoredSettings),
+			Stats:                 stats,
+			ExactCounts:           exactCounts,
+			StatsThreshold:        statsThreshold,
+			Profile:               profile,
+			ProfileOptions: internal.ProfileOptions{
+				Tolerance: profileTolerance,
+				TopN:      profileTop,
+			},
+			Data: data,
+			DataOptions: internal.DataOptions{
+				SamplePercent:  samplePercent,
+				SampleRows:     sampleRows,
+				Seed:           dataSeed,
+				MaxDifferences: dataMaxDiffs,
+				Filters:        conf.DataFilters,
+				Comparators:    conf.Comparators,
+			},
+		})
 		s.Stop()
 		if err != nil {
 			helpers.ClearLine()
-			fmt.Printf(config.ErrorStyle.Render("Error running database comparison: %s\n"), err)
+			fmt.Fprintf(os.Stderr, config.ErrorStyle.Render("Error running database comparison: %s\n"), err)
 			os.Exit(1)
 		}
 
 		helpers.ClearLine()
-		fmt.Println(config.SuccessStyle.Render("✔ Comparison finished"))
+		fmt.Fprintln(os.Stderr, config.SuccessStyle.Render("✔ Comparison finished"))
+
+		if dataSample != "" {
+			fmt.Fprintln(os.Stderr, config.InfoStyle.Render(fmt.Sprintf("Data sample seed: %d", dataSeed)))
+		}
+
+		if format == "text" && !cmd.Flags().Changed("output") && !cmd.Flags().Changed("name") {
+			fmt.Fprintln(os.Stderr)
+			if err = helpers.WriteText(os.Stdout, result, db1Name, db2Name); err != nil {
+				fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error printing result:"), err)
+				os.Exit(1)
+			}
+			return
+		}
 
 		if name == "" {
 			timestamp := time.Now().Format("20060102_150405")
 
-			outputPath += "Comparison_Result_" + timestamp + ".xlsx"
+			outputPath += "Comparison_Result_" + timestamp + helpers.FormatExtension(format)
 		} else {
-			outputPath += name + ".xlsx"
+			outputPath += name + helpers.FormatExtension(format)
 		}
 
-		err = helpers.SaveAsExcel(result, db1Name, db2Name, outputPath)
+		if helpers.IsDiagramFormat(format) {
+			var graph internal.SchemaGraph
+			graph, err = internal.BuildSchemaGraph(DB1, DB2, result)
+			if err == nil {
+				err = helpers.SaveAsDiagram(format, graph, outputPath)
+			}
+		} else {
+			err = helpers.SaveResult(format, result, db1Name, db2Name, outputPath, maxRows)
+		}
 		if err != nil {
-			fmt.Println(config.ErrorStyle.Render("Error saving result file:"), err)
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error saving result file:"), err)
 			os.Exit(1)
 		}
 
-		fmt.Println(config.SuccessStyle.Render("✔ Result file saved successfully"))
+		fmt.Fprintln(os.Stderr, config.SuccessStyle.Render("✔ Result file saved successfully"))
 	},
 }
 
@@ -112,6 +185,24 @@ func init() {
 	compareCmd.Flags().StringP("config", "c", "./db-compare-config.json", "path for the configuration file")
 	compareCmd.Flags().StringP("output", "o", "./", "path where the comparison result file is saved")
 	compareCmd.Flags().StringP("name", "n", "", "name of the comparison result file")
+	compareCmd.Flags().StringP("format", "f", "", "format of the comparison result: "+strings.Join(helpers.OutputFormats, ", ")+" (default text in a terminal without --output, excel otherwise)")
+	compareCmd.Flags().Int("max-rows", 0, "maximum number of rows per kind of difference in the Excel report, 0 keeps all of them")
 	compareCmd.Flags().String("dsn1", "", "connection string for the first database")
 	compareCmd.Flags().String("dsn2", "", "connection string for the first database")
+	compareCmd.Flags().Bool("privileges", false, "compare grants, object ownership and role attributes")
+	compareCmd.Flags().Bool("strict-partitions", false, "report every partition bound that is not present in both databases")
+	compareCmd.Flags().Bool("strict-column-order", false, "report columns whose position in the table differs")
+	compareCmd.Flags().Bool("comments", false, "compare table, column, view and function comments")
+	compareCmd.Flags().Bool("settings", false, "compare server configuration, version, encoding and locale")
+	compareCmd.Flags().StringArray("ignore-setting", []string{}, "server setting to skip in the settings comparison, in addition to host specific defaults")
+	compareCmd.Flags().Bool("stats", false, "compare estimated row counts and sizes of the tables present in both databases")
+	compareCmd.Flags().Bool("exact-counts", false, "use count(*) for the table statistics instead of the planner estimate")
+	compareCmd.Flags().Float64("stats-threshold", 10, "percentage difference in row count or size above which a table is flagged")
+	compareCmd.Flags().Bool("profile", false, "compare null ratio, distinct count, min/max, average length and top values of every column")
+	compareCmd.Flags().Float64("profile-tolerance", 5, "allowed difference in percent for the column profile metrics")
+	compareCmd.Flags().Int("profile-top", 5, "number of most frequent values compared per column")
+	compareCmd.Flags().Bool("data", false, "compare the rows of the tables present in both databases by primary key")
+	compareCmd.Flags().String("data-sample", "", "compare a deterministic sample of rows, as a percentage (0.1%) or a number of rows (10000)")
+	compareCmd.Flags().Int64("data-seed", 0, "seed used to pick the sampled rows, random when not provided")
+	compareCmd.Flags().Int("data-max-diffs", 1000, "maximum number of row differences kept per table, 0 keeps all of them")
 }
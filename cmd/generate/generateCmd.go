@@ -10,10 +10,10 @@ import (
 var GenerateCmd = &cobra.Command{
 	Use:     "generate",
 	Aliases: []string{"g"},
-	Short:   "Generate configuration file or DSN",
-	Long:    "The generate command allows generating configuration files or DSN strings for connecting to the database.",
+	Short:   "Generate configuration file, DSN, rollback script or migration",
+	Long:    "The generate command allows generating configuration files or DSN strings for connecting to the database, rollback scripts and versioned migrations for schema changes.",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(config.ErrorStyle.Render("Error: 'generate' command requires a subcommand (dsn or config)"))
+		fmt.Println(config.ErrorStyle.Render("Error: 'generate' command requires a subcommand (dsn, config, rollback or migration)"))
 		cmd.Help()
 	},
 }
@@ -21,4 +21,6 @@ var GenerateCmd = &cobra.Command{
 func init() {
 	GenerateCmd.AddCommand(dsnCmd)
 	GenerateCmd.AddCommand(configCmd)
+	GenerateCmd.AddCommand(rollbackCmd)
+	GenerateCmd.AddCommand(migrationCmd)
 }
@@ -0,0 +1,119 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+	"github.com/CDavidSV/go-dbcompare/internal/config"
+	"github.com/CDavidSV/go-dbcompare/internal/helpers"
+	"github.com/spf13/cobra"
+)
+
+var migrationCmd = &cobra.Command{
+	Use:   "migration",
+	Short: "Generates a versioned migration",
+	Long:  "Compares both databases and writes the DDL that changes the second database (target) to match the first database (source) as versioned migration files for Flyway, Liquibase, goose or golang-migrate.",
+	Run: func(cmd *cobra.Command, args []string) {
+		configFilePath, _ := cmd.Flags().GetString("config")
+		outputPath, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("migration-format")
+		description, _ := cmd.Flags().GetString("description")
+		version, _ := cmd.Flags().GetString("version")
+		dsn1, _ := cmd.Flags().GetString("dsn1")
+		dsn2, _ := cmd.Flags().GetString("dsn2")
+
+		if !slices.Contains(internal.MigrationFormats, format) {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render(fmt.Sprintf("Error: invalid migration format %s, expected one of: %s", format, strings.Join(internal.MigrationFormats, ", "))))
+			os.Exit(1)
+		}
+
+		if version == "" {
+			version = time.Now().UTC().Format("20060102150405")
+		}
+
+		conf, dsn1, dsn2, err := helpers.LoadConnections(configFilePath, dsn1, dsn2)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error reading configuration file:"), err)
+			os.Exit(1)
+		}
+
+		DB1, err := helpers.ConnectWithSpinner(conf.DB1.Name, dsn1)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), conf.DB1.Name, err)
+			os.Exit(1)
+		}
+		defer DB1.Close()
+
+		DB2, err := helpers.ConnectWithSpinner(conf.DB2.Name, dsn2)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), conf.DB2.Name, err)
+			os.Exit(1)
+		}
+		defer DB2.Close()
+
+		result, err := internal.CompareDatabase(DB1, DB2, internal.CompareOptions{})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error running database comparison:"), err)
+			os.Exit(1)
+		}
+
+		if !result.HasSchemaDifferences() {
+			fmt.Fprintln(os.Stderr, config.SuccessStyle.Render("✔ No schema differences found, no migration generated"))
+			return
+		}
+
+		up, err := internal.GenerateMigration(DB1, DB2, result)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error generating migration:"), err)
+			os.Exit(1)
+		}
+
+		down, err := internal.GenerateRollback(DB1, DB2, result)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error generating rollback script:"), err)
+			os.Exit(1)
+		}
+
+		files, err := internal.FormatMigration(format, version, description, up, down)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error:"), err)
+			os.Exit(1)
+		}
+
+		if err = os.MkdirAll(outputPath, 0o755); err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Could not create output directory:"), err)
+			os.Exit(1)
+		}
+
+		for _, file := range files {
+			path := filepath.Join(outputPath, file.Name)
+			if err = os.WriteFile(path, []byte(file.Content), 0o644); err != nil {
+				fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Could not save file:"), err)
+				os.Exit(1)
+			}
+
+			fmt.Fprintln(os.Stderr, config.SuccessStyle.Render("✔ Saved "+path))
+		}
+
+		for _, statement := range append(up, down...) {
+			if statement.Warning != "" {
+				fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Warning: "+statement.Warning))
+			}
+		}
+	},
+}
+
+func init() {
+	migrationCmd.Flags().StringP("config", "c", "./db-compare-config.json", "path for the configuration file")
+	migrationCmd.Flags().StringP("output", "o", "./migrations", "directory where the migration files are saved")
+	migrationCmd.Flags().StringP("migration-format", "f", "golang-migrate", "migration tool format: "+strings.Join(internal.MigrationFormats, ", "))
+	migrationCmd.Flags().StringP("description", "d", "sync_schema", "description used in the migration file names")
+	migrationCmd.Flags().String("version", "", "version of the migration, the current UTC timestamp when not provided")
+	migrationCmd.Flags().String("dsn1", "", "connection string for the source database")
+	migrationCmd.Flags().String("dsn2", "", "connection string for the target database")
+}
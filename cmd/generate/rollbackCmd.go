@@ -0,0 +1,83 @@
+package generate
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+	"github.com/CDavidSV/go-dbcompare/internal/config"
+	"github.com/CDavidSV/go-dbcompare/internal/helpers"
+	"github.com/spf13/cobra"
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Generates a rollback script",
+	Long:  "Compares both databases and generates the DDL that restores the current definitions of the second database (target) after it is changed to match the first database (source).",
+	Run: func(cmd *cobra.Command, args []string) {
+		configFilePath, _ := cmd.Flags().GetString("config")
+		outputPath, _ := cmd.Flags().GetString("output")
+		dsn1, _ := cmd.Flags().GetString("dsn1")
+		dsn2, _ := cmd.Flags().GetString("dsn2")
+
+		conf, dsn1, dsn2, err := helpers.LoadConnections(configFilePath, dsn1, dsn2)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error reading configuration file:"), err)
+			os.Exit(1)
+		}
+
+		DB1, err := helpers.ConnectWithSpinner(conf.DB1.Name, dsn1)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), conf.DB1.Name, err)
+			os.Exit(1)
+		}
+		defer DB1.Close()
+
+		DB2, err := helpers.ConnectWithSpinner(conf.DB2.Name, dsn2)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), conf.DB2.Name, err)
+			os.Exit(1)
+		}
+		defer DB2.Close()
+
+		result, err := internal.CompareDatabase(DB1, DB2, internal.CompareOptions{})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error running database comparison:"), err)
+			os.Exit(1)
+		}
+
+		statements, err := internal.GenerateRollback(DB1, DB2, result)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error generating rollback script:"), err)
+			os.Exit(1)
+		}
+
+		file, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Could not save file:"), err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		err = internal.WriteDDL(file, fmt.Sprintf("Rollback script for %s", conf.DB2.Name), statements)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Could not save file:"), err)
+			os.Exit(1)
+		}
+
+		for _, statement := range statements {
+			if statement.Warning != "" {
+				fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Warning: "+statement.Warning))
+			}
+		}
+
+		fmt.Fprintln(os.Stderr, config.SuccessStyle.Render(fmt.Sprintf("✔ Rollback script with %d statements saved to %s", len(statements), outputPath)))
+	},
+}
+
+func init() {
+	rollbackCmd.Flags().StringP("config", "c", "./db-compare-config.json", "path for the configuration file")
+	rollbackCmd.Flags().StringP("output", "o", "./rollback.sql", "path where the rollback script is saved")
+	rollbackCmd.Flags().String("dsn1", "", "connection string for the source database")
+	rollbackCmd.Flags().String("dsn2", "", "connection string for the target database")
+}
@@ -24,5 +24,7 @@ func Execute() {
 
 func init() {
 	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(syncSqlCmd)
+	rootCmd.AddCommand(applyCmd)
 	rootCmd.AddCommand(generate.GenerateCmd)
 }
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+	"github.com/CDavidSV/go-dbcompare/internal/config"
+	"github.com/CDavidSV/go-dbcompare/internal/helpers"
+	"github.com/briandowns/spinner"
+	"github.com/spf13/cobra"
+)
+
+var syncSqlCmd = &cobra.Command{
+	Use:   "sync-sql <table...>",
+	Short: "Generates SQL to synchronize table data",
+	Long:  "Compares the rows of the given tables by primary key and writes the INSERT, UPDATE and DELETE statements that make the second database (target) match the first one (source).",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		configFilePath, _ := cmd.Flags().GetString("config")
+		outputPath, _ := cmd.Flags().GetString("output")
+		dsn1, _ := cmd.Flags().GetString("dsn1")
+		dsn2, _ := cmd.Flags().GetString("dsn2")
+		upsert, _ := cmd.Flags().GetBool("upsert")
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+
+		conf, dsn1, dsn2, err := helpers.LoadConnections(configFilePath, dsn1, dsn2)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error reading configuration file:"), err)
+			os.Exit(1)
+		}
+
+		DB1, err := helpers.ConnectWithSpinner(conf.DB1.Name, dsn1)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), conf.DB1.Name, err)
+			os.Exit(1)
+		}
+		defer DB1.Close()
+
+		DB2, err := helpers.ConnectWithSpinner(conf.DB2.Name, dsn2)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, config.ErrorStyle.Render("Error connecting to %s\nError: %s\n"), conf.DB2.Name, err)
+			os.Exit(1)
+		}
+		defer DB2.Close()
+
+		file, err := os.Create(outputPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error creating output file:"), err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		fmt.Fprintln(os.Stderr)
+		helpers.SaveCursorPosition()
+
+		s := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriterFile(os.Stderr))
+		s.Suffix = config.InfoStyle.Render(" Comparing table data")
+		s.Start()
+
+		summaries, err := internal.WriteSyncSQL(file, DB1, DB2, args, internal.SyncOptions{
+			Upsert:    upsert,
+			BatchSize: batchSize,
+			Data: internal.DataOptions{
+				Filters:     conf.DataFilters,
+				Comparators: conf.Comparators,
+			},
+		})
+		s.Stop()
+		helpers.ClearLine()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, config.ErrorStyle.Render("Error generating synchronization script:"), err)
+			os.Exit(1)
+		}
+
+		for _, summary := range summaries {
+			fmt.Fprintf(os.Stderr, "%s: %d inserts, %d updates, %d deletes\n", summary.TableName, summary.Inserts, summary.Updates, summary.Deletes)
+			if summary.NotWritable > 0 {
+				fmt.Fprintln(os.Stderr, config.WarningStyle.Render(fmt.Sprintf("%s: %d rows only differ in generated or identity columns and are not updated", summary.TableName, summary.NotWritable)))
+			}
+		}
+
+		fmt.Fprintln(os.Stderr, config.SuccessStyle.Render(fmt.Sprintf("✔ Synchronization script saved to %s", outputPath)))
+	},
+}
+
+func init() {
+	syncSqlCmd.Flags().StringP("config", "c", "./db-compare-config.json", "path for the configuration file")
+	syncSqlCmd.Flags().StringP("output", "o", "./sync.sql", "path where the SQL script is saved")
+	syncSqlCmd.Flags().String("dsn1", "", "connection string for the source database")
+	syncSqlCmd.Flags().String("dsn2", "", "connection string for the target database")
+	syncSqlCmd.Flags().Bool("upsert", false, "write inserts and updates as INSERT ... ON CONFLICT statements")
+	syncSqlCmd.Flags().Int("batch-size", 500, "number of rows per INSERT and DELETE statement")
+}
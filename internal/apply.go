@@ -0,0 +1,216 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// transactionControl matches statements that would start or end the transaction the script runs in,
+// including the two-phase commit statements. Savepoints and ROLLBACK TO are left to the server.
+var transactionControl = regexp.MustCompile(`(?is)^(?:(?:begin|start\s+transaction|prepare\s+transaction|(?:commit|rollback)\s+prepared)\b.*|(?:commit|end|rollback|abort)(?:\s+(?:work|transaction))?(?:\s+and\s+(?:no\s+)?chain)?)$`)
+
+type StatementResult struct {
+	Number    int
+	Statement string
+	Skipped   bool
+	Error     error
+}
+
+// SplitStatements splits a SQL script into its statements. Semicolons inside string literals,
+// quoted identifiers, dollar quoted bodies and comments do not end a statement.
+func SplitStatements(script string) []string {
+	statements := []string{}
+	var sb strings.Builder
+
+	flush := func() {
+		statement := strings.TrimSpace(sb.String())
+		sb.Reset()
+
+		if stripComments(statement) != "" {
+			statements = append(statements, statement)
+		}
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+
+		switch {
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end == -1 {
+				end = len(script) - i
+			}
+			sb.WriteString(script[i : i+end])
+			i += end - 1
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := blockCommentEnd(script, i)
+			sb.WriteString(script[i:end])
+			i = end - 1
+		case c == '\'' || c == '"':
+			// E'' strings allow backslash escapes
+			escapes := c == '\'' && i > 0 && (script[i-1] == 'E' || script[i-1] == 'e')
+			end := quoteEnd(script, i, c, escapes)
+			sb.WriteString(script[i:end])
+			i = end - 1
+		case c == '$':
+			tag := ""
+			if i == 0 || !isIdentifierChar(script[i-1]) {
+				tag = dollarTag(script[i:])
+			}
+
+			if tag == "" {
+				sb.WriteByte(c)
+				continue
+			}
+
+			end := strings.Index(script[i+len(tag):], tag)
+			if end == -1 {
+				end = len(script)
+			} else {
+				end = i + len(tag) + end + len(tag)
+			}
+			sb.WriteString(script[i:end])
+			i = end - 1
+		case c == ';':
+			flush()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	flush()
+
+	return statements
+}
+
+func blockCommentEnd(script string, start int) int {
+	depth := 0
+	for i := start; i < len(script)-1; i++ {
+		switch script[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+
+	return len(script)
+}
+
+func quoteEnd(script string, start int, quote byte, escapes bool) int {
+	for i := start + 1; i < len(script); i++ {
+		switch {
+		case escapes && script[i] == '\\':
+			i++
+		case script[i] == quote:
+			// Doubled quotes are an escaped quote
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+
+	return len(script)
+}
+
+var dollarTagPattern = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func dollarTag(s string) string {
+	return dollarTagPattern.FindString(s)
+}
+
+var lineComment = regexp.MustCompile(`--[^\n]*`)
+var blockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+
+func stripComments(statement string) string {
+	statement = blockComment.ReplaceAllString(statement, "")
+	statement = lineComment.ReplaceAllString(statement, "")
+
+	return strings.TrimSpace(statement)
+}
+
+// ExecuteScript runs every statement inside the transaction. Each statement runs in its own savepoint
+// so a failing statement is rolled back on its own and the remaining statements are still checked.
+// Transaction control statements are skipped since the caller owns the transaction.
+func ExecuteScript(tx *sql.Tx, statements []string) ([]StatementResult, error) {
+	results := []StatementResult{}
+
+	for i, statement := range statements {
+		result := StatementResult{Number: i + 1, Statement: statement}
+
+		if transactionControl.MatchString(stripComments(statement)) {
+			result.Skipped = true
+			results = append(results, result)
+			continue
+		}
+
+		if _, err := tx.Exec("SAVEPOINT dbcompare_statement"); err != nil {
+			return results, err
+		}
+
+		if _, err := tx.Exec(statement); err != nil {
+			result.Error = err
+
+			if _, err := tx.Exec("ROLLBACK TO SAVEPOINT dbcompare_statement"); err != nil {
+				return results, err
+			}
+		} else if _, err := tx.Exec("RELEASE SAVEPOINT dbcompare_statement"); err != nil {
+			return results, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// Failed returns the statements that returned an error.
+func Failed(results []StatementResult) []StatementResult {
+	failed := []StatementResult{}
+	for _, result := range results {
+		if result.Error != nil {
+			failed = append(failed, result)
+		}
+	}
+
+	return failed
+}
+
+// Skipped returns the transaction control statements that were not executed.
+func Skipped(results []StatementResult) []StatementResult {
+	skipped := []StatementResult{}
+	for _, result := range results {
+		if result.Skipped {
+			skipped = append(skipped, result)
+		}
+	}
+
+	return skipped
+}
+
+// Summary returns the first line of the statement, used to identify it in reports.
+func (r StatementResult) Summary() string {
+	line, _, _ := strings.Cut(stripComments(r.Statement), "\n")
+	if len(line) > 80 {
+		line = line[:77] + "..."
+	}
+
+	return fmt.Sprintf("#%d %s", r.Number, line)
+}
+
+// HasSchemaDifferences reports whether the tables or columns of both databases differ.
+func (c ComparisonResult) HasSchemaDifferences() bool {
+	return len(c.Changes) > 0
+}
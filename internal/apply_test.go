@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "simple statements",
+			script: "CREATE TABLE a (id int);\nDROP TABLE b;",
+			want:   []string{"CREATE TABLE a (id int)", "DROP TABLE b"},
+		},
+		{
+			name:   "missing final semicolon and empty statements",
+			script: ";;SELECT 1;  ;\nSELECT 2",
+			want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:   "semicolon in string literal",
+			script: "INSERT INTO a VALUES ('x;y');SELECT 1;",
+			want:   []string{"INSERT INTO a VALUES ('x;y')", "SELECT 1"},
+		},
+		{
+			name:   "doubled quote",
+			script: "SELECT 'it''s;';SELECT 2;",
+			want:   []string{"SELECT 'it''s;'", "SELECT 2"},
+		},
+		{
+			name:   "escape string",
+			script: `SELECT E'a\';b';SELECT 2;`,
+			want:   []string{`SELECT E'a\';b'`, "SELECT 2"},
+		},
+		{
+			name:   "quoted identifier",
+			script: `SELECT 1 AS "a;b";SELECT 2;`,
+			want:   []string{`SELECT 1 AS "a;b"`, "SELECT 2"},
+		},
+		{
+			name:   "dollar quoted body",
+			script: "CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql;SELECT 1;",
+			want:   []string{"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql", "SELECT 1"},
+		},
+		{
+			name:   "tagged dollar quote",
+			script: "DO $body$ BEGIN PERFORM 1; END $body$;SELECT 1;",
+			want:   []string{"DO $body$ BEGIN PERFORM 1; END $body$", "SELECT 1"},
+		},
+		{
+			name:   "positional parameters are not dollar quotes",
+			script: "PREPARE p AS SELECT $1;EXECUTE p(1);",
+			want:   []string{"PREPARE p AS SELECT $1", "EXECUTE p(1)"},
+		},
+		{
+			name:   "line comment",
+			script: "SELECT 1; -- comment; with semicolon\nSELECT 2;",
+			want:   []string{"SELECT 1", "-- comment; with semicolon\nSELECT 2"},
+		},
+		{
+			name:   "nested block comment",
+			script: "/* outer /* inner; */ still; */ SELECT 1;",
+			want:   []string{"/* outer /* inner; */ still; */ SELECT 1"},
+		},
+		{
+			name:   "comment only",
+			script: "SELECT 1;\n-- trailing comment\n",
+			want:   []string{"SELECT 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransactionControl(t *testing.T) {
+	tests := []struct {
+		statement string
+		want      bool
+	}{
+		{"BEGIN", true},
+		{"begin isolation level serializable", true},
+		{"START TRANSACTION READ WRITE", true},
+		{"COMMIT", true},
+		{"commit work", true},
+		{"END", true},
+		{"ROLLBACK", true},
+		{"ROLLBACK AND NO CHAIN", true},
+		{"ABORT", true},
+		{"ROLLBACK TO SAVEPOINT before_update", false},
+		{"rollback to before_update", false},
+		{"PREPARE TRANSACTION 'tx'", true},
+		{"COMMIT PREPARED 'tx'", true},
+		{"rollback  prepared 'tx'", true},
+		{"PREPARE p AS SELECT 1", false},
+		{"SAVEPOINT before_update", false},
+		{"UPDATE ending SET a = 1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.statement, func(t *testing.T) {
+			if got := transactionControl.MatchString(tt.statement); got != tt.want {
+				t.Errorf("transactionControl.MatchString(%q) = %v, want %v", tt.statement, got, tt.want)
+			}
+		})
+	}
+}
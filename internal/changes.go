@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"sort"
+)
+
+// ObjectKind is the kind of database object a change applies to.
+type ObjectKind string
+
+const (
+	ObjectTable  ObjectKind = "table"
+	ObjectColumn ObjectKind = "column"
+)
+
+// ChangeType describes how an object differs between both databases. Changes are expressed from the
+// second database (target) to the first one (source), the same direction as the generated migrations,
+// so added objects only exist in the first database and removed objects only in the second one.
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "modified"
+)
+
+// AttributeChange holds the value of an attribute before and after the change. Before is the value in
+// the second database and After the value in the first one. A nil value means the object does not
+// exist in that database, which is different from an attribute whose value is null.
+type AttributeChange struct {
+	Name   string
+	Before *string
+	After  *string
+}
+
+// Changed reports whether the attribute differs between both databases.
+func (a AttributeChange) Changed() bool {
+	if a.Before == nil || a.After == nil {
+		return a.Before != a.After
+	}
+
+	return *a.Before != *a.After
+}
+
+// displayValue returns the value as shown in the reports. Absent values are left empty so they are not
+// mistaken for a null value, which is shown as "Null".
+func displayValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+
+	return *v
+}
+
+// DB1 returns the value in the first database for display, empty when the object does not exist there.
+func (a AttributeChange) DB1() string {
+	return displayValue(a.After)
+}
+
+// DB2 returns the value in the second database for display, empty when the object does not exist there.
+func (a AttributeChange) DB2() string {
+	return displayValue(a.Before)
+}
+
+// Change is a single difference between both databases.
+type Change struct {
+	Kind ObjectKind
+	// Name is the qualified name of the object, schema.table or schema.table.column
+	Name   string
+	Table  string
+	Column string
+	Type   ChangeType
+	// Attributes lists every attribute of the object, changed or not
+	Attributes []AttributeChange
+	// Before and After are the column definitions in the second and first database. They are nil
+	// when the column does not exist there or the change is not about a column.
+	Before *ColumnData
+	After  *ColumnData
+}
+
+// ChangedAttributes returns the attributes whose value differs between both databases.
+func (c Change) ChangedAttributes() []AttributeChange {
+	changed := []AttributeChange{}
+	for _, attr := range c.Attributes {
+		if attr.Changed() {
+			changed = append(changed, attr)
+		}
+	}
+
+	return changed
+}
+
+// Attribute returns the attribute with the given name.
+func (c Change) Attribute(name string) (AttributeChange, bool) {
+	for _, attr := range c.Attributes {
+		if attr.Name == name {
+			return attr, true
+		}
+	}
+
+	return AttributeChange{}, false
+}
+
+// position is the ordinal position of the column in the database where it exists, the first one
+// when it exists in both.
+func (c Change) position() int {
+	if c.After != nil {
+		return c.After.OrdinalPosition
+	}
+	if c.Before != nil {
+		return c.Before.OrdinalPosition
+	}
+
+	return 0
+}
+
+// tableChange returns the change for a table that only exists in one of the databases.
+func tableChange(tableName string, changeType ChangeType) Change {
+	return Change{
+		Kind:  ObjectTable,
+		Name:  QualifiedName(tableName),
+		Table: tableName,
+		Type:  changeType,
+	}
+}
+
+// columnChange returns the change between the definitions of a column. before or after is nil when
+// the column does not exist in that database. The ordinal position is only part of the attributes
+// when strictOrder is set, the same as when comparing the columns.
+func columnChange(before, after *ColumnData, strictOrder bool) Change {
+	change := Change{Kind: ObjectColumn, Type: ChangeModified, Before: before, After: after}
+
+	var beforeAttributes, afterAttributes []ColumnAttribute
+	switch {
+	case before == nil:
+		change.Type = ChangeAdded
+		change.Table, change.Column = after.TableName, after.ColumnName
+		afterAttributes = after.ComparedAttributes(strictOrder)
+	case after == nil:
+		change.Type = ChangeRemoved
+		change.Table, change.Column = before.TableName, before.ColumnName
+		beforeAttributes = before.ComparedAttributes(strictOrder)
+	default:
+		change.Table, change.Column = after.TableName, after.ColumnName
+		beforeAttributes, afterAttributes = before.ComparedAttributes(strictOrder), after.ComparedAttributes(strictOrder)
+	}
+	change.Name = QualifiedName(change.Table, change.Column)
+
+	names := afterAttributes
+	if names == nil {
+		names = beforeAttributes
+	}
+	for i, attr := range names {
+		attribute := AttributeChange{Name: attr.Name}
+		if beforeAttributes != nil {
+			attribute.Before = &beforeAttributes[i].Value
+		}
+		if afterAttributes != nil {
+			attribute.After = &afterAttributes[i].Value
+		}
+		change.Attributes = append(change.Attributes, attribute)
+	}
+
+	return change
+}
+
+// sortChanges orders the changes by table, with the table change first and its columns by position.
+func sortChanges(changes []Change) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		if a.Kind != b.Kind {
+			return a.Kind == ObjectTable
+		}
+		if a.position() != b.position() {
+			return a.position() < b.position()
+		}
+
+		return a.Column < b.Column
+	})
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortChanges(t *testing.T) {
+	column := func(table, name string, position int) Change {
+		return Change{Kind: ObjectColumn, Table: table, Column: name, After: &ColumnData{OrdinalPosition: position}}
+	}
+	removedColumn := func(table, name string, position int) Change {
+		return Change{Kind: ObjectColumn, Table: table, Column: name, Before: &ColumnData{OrdinalPosition: position}}
+	}
+
+	tests := []struct {
+		name    string
+		changes []Change
+		want    []string
+	}{
+		{
+			name:    "tables by name",
+			changes: []Change{tableChange("orders", ChangeAdded), tableChange("customers", ChangeRemoved)},
+			want:    []string{"customers.", "orders."},
+		},
+		{
+			name:    "table change before its columns",
+			changes: []Change{column("orders", "id", 1), tableChange("orders", ChangeAdded)},
+			want:    []string{"orders.", "orders.id"},
+		},
+		{
+			name:    "columns by position",
+			changes: []Change{column("orders", "total", 3), column("orders", "id", 1), removedColumn("orders", "status", 2)},
+			want:    []string{"orders.id", "orders.status", "orders.total"},
+		},
+		{
+			name:    "columns with the same position by name",
+			changes: []Change{column("orders", "total", 2), removedColumn("orders", "amount", 2)},
+			want:    []string{"orders.amount", "orders.total"},
+		},
+		{
+			name:    "tables before positions",
+			changes: []Change{column("orders", "id", 1), column("customers", "name", 2)},
+			want:    []string{"customers.name", "orders.id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortChanges(tt.changes)
+
+			got := []string{}
+			for _, change := range tt.changes {
+				got = append(got, change.Table+"."+change.Column)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnChangeOrdinalPosition(t *testing.T) {
+	before := &ColumnData{TableName: "orders", ColumnName: "total", OrdinalPosition: 2, DataType: "integer"}
+	after := &ColumnData{TableName: "orders", ColumnName: "total", OrdinalPosition: 3, DataType: "bigint"}
+
+	tests := []struct {
+		name        string
+		strictOrder bool
+		want        []string
+	}{
+		{name: "position ignored", strictOrder: false, want: []string{"Data Type"}},
+		{name: "strict column order", strictOrder: true, want: []string{"Ordinal Position", "Data Type"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, attr := range columnChange(before, after, tt.strictOrder).ChangedAttributes() {
+				got = append(got, attr.Name)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangedAttributes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"database/sql"
+	"sort"
+)
+
+type CommentData struct {
+	ObjectType string
+	ObjectName string
+	Comment    NullString
+}
+
+// CommentDifference describes documentation drift between objects present in both databases.
+type CommentDifference struct {
+	ObjectType string
+	ObjectName string
+	DB1        string
+	DB2        string
+	// Change is always modified, a missing comment is a null value of an object present in both databases
+	Change   ChangeType
+	Severity Severity
+}
+
+// GetDBComments returns the comments of the tables, views, columns and functions in the public schema.
+func GetDBComments(db *sql.DB) (map[string]CommentData, error) {
+	rows, err := db.Query(`SELECT
+		CASE c.relkind WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' ELSE 'TABLE' END,
+		c.relname::text, obj_description(c.oid, 'pg_class')
+	FROM pg_class c
+	INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p', 'v', 'm') AND NOT c.relispartition
+	UNION ALL
+	SELECT
+		'COLUMN', c.relname || '.' || a.attname, col_description(c.oid, a.attnum)
+	FROM pg_class c
+	INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+	INNER JOIN pg_attribute a ON a.attrelid = c.oid
+	WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p', 'v', 'm') AND NOT c.relispartition
+		AND a.attnum > 0 AND NOT a.attisdropped
+	UNION ALL
+	SELECT
+		'FUNCTION', p.proname || '(' || pg_get_function_identity_arguments(p.oid) || ')', obj_description(p.oid, 'pg_proc')
+	FROM pg_proc p
+	INNER JOIN pg_namespace n ON n.oid = p.pronamespace
+	WHERE n.nspname = 'public'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := map[string]CommentData{}
+	for rows.Next() {
+		var comment CommentData
+
+		err = rows.Scan(&comment.ObjectType, &comment.ObjectName, &comment.Comment)
+		if err != nil {
+			return nil, err
+		}
+
+		comments[comment.ObjectType+"|"+comment.ObjectName] = comment
+	}
+
+	return comments, rows.Err()
+}
+
+// CompareComments compares the comments of the objects present in both databases.
+// Differences are classed as low severity since they do not affect behaviour.
+func CompareComments(DB1Comments, DB2Comments map[string]CommentData) []CommentDifference {
+	differences := []CommentDifference{}
+
+	for key, DB1Value := range DB1Comments {
+		DB2Value, ok := DB2Comments[key]
+		if !ok {
+			continue
+		}
+
+		if DB1Value.Comment != DB2Value.Comment {
+			differences = append(differences, CommentDifference{
+				ObjectType: DB1Value.ObjectType,
+				ObjectName: DB1Value.ObjectName,
+				DB1:        string(DB1Value.Comment),
+				DB2:        string(DB2Value.Comment),
+				Change:     ChangeModified,
+				Severity:   SeverityLow,
+			})
+		}
+	}
+
+	sort.Slice(differences, func(i, j int) bool {
+		if differences[i].ObjectType != differences[j].ObjectType {
+			return differences[i].ObjectType < differences[j].ObjectType
+		}
+		return differences[i].ObjectName < differences[j].ObjectName
+	})
+
+	return differences
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareComments(t *testing.T) {
+	comments := func(values ...CommentData) map[string]CommentData {
+		m := map[string]CommentData{}
+		for _, c := range values {
+			m[c.ObjectType+"|"+c.ObjectName] = c
+		}
+		return m
+	}
+
+	tests := []struct {
+		name string
+		DB1  map[string]CommentData
+		DB2  map[string]CommentData
+		want []CommentDifference
+	}{
+		{
+			name: "same comments",
+			DB1:  comments(CommentData{ObjectType: "TABLE", ObjectName: "orders", Comment: "Customer orders"}),
+			DB2:  comments(CommentData{ObjectType: "TABLE", ObjectName: "orders", Comment: "Customer orders"}),
+			want: []CommentDifference{},
+		},
+		{
+			name: "different comments",
+			DB1:  comments(CommentData{ObjectType: "COLUMN", ObjectName: "orders.total", Comment: "Total in cents"}),
+			DB2:  comments(CommentData{ObjectType: "COLUMN", ObjectName: "orders.total", Comment: "Total"}),
+			want: []CommentDifference{{ObjectType: "COLUMN", ObjectName: "orders.total", DB1: "Total in cents", DB2: "Total", Change: ChangeModified, Severity: SeverityLow}},
+		},
+		{
+			name: "missing comment",
+			DB1:  comments(CommentData{ObjectType: "FUNCTION", ObjectName: "total(integer)", Comment: "Order total"}),
+			DB2:  comments(CommentData{ObjectType: "FUNCTION", ObjectName: "total(integer)", Comment: "Null"}),
+			want: []CommentDifference{{ObjectType: "FUNCTION", ObjectName: "total(integer)", DB1: "Order total", DB2: "Null", Change: ChangeModified, Severity: SeverityLow}},
+		},
+		{
+			name: "missing objects are ignored",
+			DB1:  comments(CommentData{ObjectType: "TABLE", ObjectName: "orders", Comment: "Customer orders"}),
+			DB2:  comments(CommentData{ObjectType: "VIEW", ObjectName: "totals", Comment: "Order totals"}),
+			want: []CommentDifference{},
+		},
+		{
+			name: "sorted by type and name",
+			DB1: comments(
+				CommentData{ObjectType: "TABLE", ObjectName: "orders", Comment: "a"},
+				CommentData{ObjectType: "COLUMN", ObjectName: "orders.total", Comment: "a"},
+				CommentData{ObjectType: "COLUMN", ObjectName: "orders.id", Comment: "a"},
+			),
+			DB2: comments(
+				CommentData{ObjectType: "TABLE", ObjectName: "orders", Comment: "b"},
+				CommentData{ObjectType: "COLUMN", ObjectName: "orders.total", Comment: "b"},
+				CommentData{ObjectType: "COLUMN", ObjectName: "orders.id", Comment: "b"},
+			),
+			want: []CommentDifference{
+				{ObjectType: "COLUMN", ObjectName: "orders.id", DB1: "a", DB2: "b", Change: ChangeModified, Severity: SeverityLow},
+				{ObjectType: "COLUMN", ObjectName: "orders.total", DB1: "a", DB2: "b", Change: ChangeModified, Severity: SeverityLow},
+				{ObjectType: "TABLE", ObjectName: "orders", DB1: "a", DB2: "b", Change: ChangeModified, Severity: SeverityLow},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareComments(tt.DB1, tt.DB2); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareComments() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
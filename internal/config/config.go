@@ -6,4 +6,6 @@ var (
 	ErrorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
 	InfoStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
 	SuccessStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	WarningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFB000"))
+	TitleStyle   = lipgloss.NewStyle().Bold(true)
 )
@@ -1,55 +1,117 @@
 package internal
 
 import (
-	"encoding/json"
 	"fmt"
-	"math"
+	"strings"
 )
 
-func GenerateCSVData(differences ComparisonResult, DB1Name, DB2Name string) ([][]string, error) {
-	data := [][]string{
-		{fmt.Sprintf("Database 1 (%s)", DB1Name), fmt.Sprintf("Database 2 (%s)", DB2Name), "", "", fmt.Sprintf("Tables missing in %s", DB1Name), fmt.Sprintf("Tables missing in %s", DB2Name)},
-	}
+// CSVHeaders are the columns of the differences CSV. Every row is a single difference. Key is the
+// primary key of the row for row differences.
+var CSVHeaders = []string{"Object Type", "Schema", "Table", "Column", "Key", "Attribute", "DB1 Value", "DB2 Value", "Change Kind"}
+
+// MissingCSVHeaders are the columns of the CSV listing the objects missing in one of the databases.
+var MissingCSVHeaders = []string{"Object Type", "Schema", "Table", "Column", "Present In", "Missing In"}
+
+func csvRow(objectType, schema, table, column, key, attribute, DB1Value, DB2Value string, change ChangeType) []string {
+	return []string{objectType, schema, table, column, key, attribute, DB1Value, DB2Value, string(change)}
+}
+
+// GenerateCSVData returns the differences as flat rows, one per difference, and the tables and
+// columns that only exist in one of the databases. Both include their header row.
+func GenerateCSVData(differences ComparisonResult, DB1Name, DB2Name string) ([][]string, [][]string) {
+	data := [][]string{CSVHeaders}
+	missing := [][]string{MissingCSVHeaders}
 
-	for i, v := range differences.DifferencesResult.DB1 {
-		v2 := differences.DifferencesResult.DB2[i]
+	for _, change := range differences.Changes {
+		present, absent := DB1Name, DB2Name
+		if change.Type == ChangeRemoved {
+			present, absent = DB2Name, DB1Name
+		}
 
-		jsonStringDB1, err := json.MarshalIndent(v, "", "    ")
-		if err != nil {
-			return nil, err
+		if change.Kind == ObjectTable {
+			missing = append(missing, []string{"Table", Schema, change.Table, "", present, absent})
+			continue
 		}
 
-		jsonStringDB2, err := json.MarshalIndent(v2, "", "    ")
-		if err != nil {
-			return nil, err
+		switch change.Type {
+		case ChangeAdded, ChangeRemoved:
+			missing = append(missing, []string{"Column", Schema, change.Table, change.Column, present, absent})
+			if attr, ok := change.Attribute("Data Type"); ok {
+				data = append(data, csvRow("Column", Schema, change.Table, change.Column, "", attr.Name, attr.DB1(), attr.DB2(), change.Type))
+			}
+		default:
+			for _, attr := range change.ChangedAttributes() {
+				data = append(data, csvRow("Column", Schema, change.Table, change.Column, "", attr.Name, attr.DB1(), attr.DB2(), change.Type))
+			}
+		}
+	}
+
+	for _, v := range differences.PolicyDifferences {
+		data = append(data, csvRow("Policy", Schema, v.TableName, "", "", strings.TrimSpace(v.PolicyName+" "+v.Attribute), v.DB1, v.DB2, v.Change))
+	}
+
+	for _, v := range differences.PartitionDiffs {
+		data = append(data, csvRow("Partition", Schema, v.TableName, "", "", v.Attribute, v.DB1, v.DB2, v.Change))
+	}
+
+	for _, v := range differences.CommentDifferences {
+		table, column := v.ObjectName, ""
+		if v.ObjectType == "COLUMN" {
+			table, column, _ = strings.Cut(v.ObjectName, ".")
 		}
+		data = append(data, csvRow(v.ObjectType, Schema, table, column, "", "Comment", v.DB1, v.DB2, v.Change))
+	}
 
-		comparisonData := []string{string(jsonStringDB1), string(jsonStringDB2)}
+	for _, v := range differences.ProfileDifferences {
+		data = append(data, csvRow("Column Profile", Schema, v.TableName, v.ColumnName, "", v.Metric, v.DB1, v.DB2, v.Change))
+	}
+
+	for _, v := range differences.TableStats {
+		if !v.Flagged {
+			continue
+		}
 
-		data = append(data, comparisonData)
+		data = append(data, csvRow("Table Statistics", Schema, v.TableName, "", "", "Rows", fmt.Sprint(v.DB1.Rows()), fmt.Sprint(v.DB2.Rows()), ChangeModified))
+		data = append(data, csvRow("Table Statistics", Schema, v.TableName, "", "", "Total Size", fmt.Sprint(v.DB1.TotalSize), fmt.Sprint(v.DB2.TotalSize), ChangeModified))
 	}
 
-	// Get the length of the longest slice
-	length := int(math.Max(float64(len(differences.MissingTablesInDB1)), float64(len(differences.MissingTablesInDB2))))
+	if differences.Privileges != nil {
+		for _, v := range differences.Privileges.Privileges {
+			data = append(data, csvRow("Privilege", "", v.ObjectName, "", "", fmt.Sprintf("%s %s to %s", v.ObjectType, v.Privilege, v.Grantee), v.DB1, v.DB2, v.Change))
+		}
 
-	dataIndex := 1
-	for i := range length {
-		tableDB1 := differences.MissingTablesInDB1[i]
-		tableDB2 := ""
+		for _, v := range differences.Privileges.Owners {
+			data = append(data, csvRow("Owner", "", v.ObjectName, "", "", fmt.Sprintf("%s Owner", v.ObjectType), v.DB1, v.DB2, v.Change))
+		}
 
-		if i <= len(differences.MissingTablesInDB2) {
-			tableDB2 = differences.MissingTablesInDB2[i]
+		for _, v := range differences.Privileges.Roles {
+			data = append(data, csvRow("Role", "", "", "", "", fmt.Sprintf("%s %s", v.RoleName, v.Attribute), v.DB1, v.DB2, v.Change))
 		}
+	}
 
-		// First determine if the index is null
-		if dataIndex >= len(data) {
-			data = append(data, []string{"", "", "", "", tableDB1, tableDB2})
-		} else {
-			data[dataIndex] = append(data[dataIndex], "", "", tableDB1, tableDB2)
+	if differences.Settings != nil {
+		for _, v := range differences.Settings.Differences {
+			data = append(data, csvRow("Setting", "", "", "", "", v.Name, v.DB1, v.DB2, v.Change))
 		}
+	}
 
-		dataIndex++
+	if differences.Data != nil {
+		for _, table := range differences.Data.Tables {
+			for _, diff := range table.Differences {
+				switch {
+				case diff.DB2 == nil:
+					data = append(data, csvRow("Row", Schema, diff.TableName, "", diff.Key, "Row", diff.DB1.String(table.Columns), "", diff.Change()))
+				case diff.DB1 == nil:
+					data = append(data, csvRow("Row", Schema, diff.TableName, "", diff.Key, "Row", "", diff.DB2.String(table.Columns), diff.Change()))
+				default:
+					// One record per changed column
+					for _, col := range diff.Columns {
+						data = append(data, csvRow("Row", Schema, diff.TableName, col, diff.Key, "Value", diff.DB1.Value(col), diff.DB2.Value(col), diff.Change()))
+					}
+				}
+			}
+		}
 	}
 
-	return data, nil
+	return data, missing
 }
@@ -0,0 +1,454 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// sampleBuckets is the number of buckets primary key hashes are split into when sampling.
+const sampleBuckets = 1000000
+
+type DataOptions struct {
+	// SamplePercent and SampleRows select a subset of the rows to compare. When both
+	// are zero every row is compared.
+	SamplePercent float64
+	SampleRows    int64
+	Seed          int64
+	// MaxDifferences limits the row differences kept per table, 0 keeps all of them
+	MaxDifferences int
+	Filters        DataFilters
+	Comparators    Comparators
+}
+
+// Row maps a column name to its value as text.
+type Row map[string]sql.NullString
+
+// RowDifference describes a row that differs between both databases. DB1 or DB2 is nil
+// when the row does not exist in that database.
+type RowDifference struct {
+	TableName string
+	Key       string
+	Columns   []string
+	DB1       Row
+	DB2       Row
+}
+
+// Change returns added when the row only exists in the first database, removed when it only exists
+// in the second one and modified otherwise.
+func (d RowDifference) Change() ChangeType {
+	switch {
+	case d.DB2 == nil:
+		return ChangeAdded
+	case d.DB1 == nil:
+		return ChangeRemoved
+	default:
+		return ChangeModified
+	}
+}
+
+type TableDataComparison struct {
+	TableName    string
+	Skipped      string
+	Filter       string
+	Columns      []string
+	PrimaryKey   []string
+	RowsCompared int64
+	Mismatches   int64
+	Differences  []RowDifference
+	// Estimated mismatch rate and 95% confidence interval, in percent
+	MismatchRate float64
+	RateLow      float64
+	RateHigh     float64
+}
+
+type DataComparison struct {
+	Sample string
+	Seed   int64
+	Tables []TableDataComparison
+}
+
+// ParseDataSample parses a sample size given either as a percentage ("0.1%") or as a number of rows ("10000").
+func ParseDataSample(value string) (float64, int64, error) {
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		p, err := strconv.ParseFloat(percent, 64)
+		if err != nil || p <= 0 || p > 100 {
+			return 0, 0, fmt.Errorf("sample percentage must be a number between 0 and 100. Got: %s", value)
+		}
+
+		return p, 0, nil
+	}
+
+	rows, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || rows <= 0 {
+		return 0, 0, fmt.Errorf("sample must be a percentage (e.g. 0.1%%) or a positive number of rows. Got: %s", value)
+	}
+
+	return 0, rows, nil
+}
+
+// Value returns the value of the column for display, "Null" when it is null.
+func (r Row) Value(column string) string {
+	if !r[column].Valid {
+		return "Null"
+	}
+
+	return r[column].String
+}
+
+// String formats the row as a list of column=value pairs in the given column order.
+func (r Row) String(columns []string) string {
+	values := []string{}
+	for _, col := range columns {
+		values = append(values, fmt.Sprintf("%s=%s", col, r.Value(col)))
+	}
+
+	return strings.Join(values, ", ")
+}
+
+func GetPrimaryKey(db *sql.DB, tableName string) ([]string, error) {
+	rows, err := db.Query(`SELECT
+		a.attname::text
+	FROM pg_index i
+	INNER JOIN pg_class c ON c.oid = i.indrelid
+	INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+	CROSS JOIN LATERAL unnest(i.indkey) WITH ORDINALITY AS k(attnum, position)
+	INNER JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = k.attnum
+	WHERE n.nspname = 'public' AND c.relname = $1 AND i.indisprimary
+	ORDER BY k.position`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func quoteIdentifiers(names []string) []string {
+	quoted := []string{}
+	for _, name := range names {
+		quoted = append(quoted, pq.QuoteIdentifier(name))
+	}
+
+	return quoted
+}
+
+// rowKey returns the expression used to identify a row. It is ordered with the "C" collation
+// so both result sets can be merged comparing keys byte by byte.
+func rowKey(primaryKey []string) string {
+	return fmt.Sprintf(`(ROW(%s)::text) COLLATE "C"`, strings.Join(quoteIdentifiers(primaryKey), ", "))
+}
+
+// samplePredicate selects the rows whose primary key hash falls in the first buckets. The
+// selection only depends on the key and seed, so both databases pick the same keys.
+func samplePredicate(primaryKey []string, seed int64, fraction float64) string {
+	threshold := int64(math.Ceil(fraction * sampleBuckets))
+
+	return fmt.Sprintf("abs(hashtextextended(ROW(%s)::text, %d) %% %d) < %d", strings.Join(quoteIdentifiers(primaryKey), ", "), seed, sampleBuckets, threshold)
+}
+
+func dataQuery(tableName string, columns, primaryKey []string, where string) string {
+	selectCols := []string{rowKey(primaryKey)}
+	for _, col := range quoteIdentifiers(columns) {
+		selectCols = append(selectCols, col+"::text")
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selectCols, ", "), qualifiedTable(tableName))
+	if where != "" {
+		query += " WHERE " + where
+	}
+
+	return query + " ORDER BY 1"
+}
+
+func scanRow(rows *sql.Rows, columns []string) (string, Row, error) {
+	var key string
+	values := make([]sql.NullString, len(columns))
+
+	dest := []any{&key}
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return "", nil, err
+	}
+
+	row := Row{}
+	for i, col := range columns {
+		row[col] = values[i]
+	}
+
+	return key, row, nil
+}
+
+// nextRow advances the result set and returns an empty key when there are no rows left.
+func nextRow(rows *sql.Rows, columns []string) (string, Row, bool, error) {
+	if !rows.Next() {
+		return "", nil, false, rows.Err()
+	}
+
+	key, row, err := scanRow(rows, columns)
+	return key, row, true, err
+}
+
+// changedColumns returns the columns whose values differ, applying the column comparators
+// before declaring a mismatch.
+func changedColumns(tableName string, columns []string, DB1Row, DB2Row Row, comparators Comparators) []string {
+	changed := []string{}
+	for _, col := range columns {
+		if DB1Row[col] == DB2Row[col] {
+			continue
+		}
+
+		if comparator, ok := comparators.For(tableName, col); ok && comparator.Equal(DB1Row[col], DB2Row[col]) {
+			continue
+		}
+
+		changed = append(changed, col)
+	}
+
+	return changed
+}
+
+// wilsonInterval returns the 95% confidence interval of a proportion.
+func wilsonInterval(mismatches, total int64) (float64, float64) {
+	if total == 0 {
+		return 0, 0
+	}
+
+	z := 1.96
+	n := float64(total)
+	p := float64(mismatches) / n
+
+	denominator := 1 + z*z/n
+	center := (p + z*z/(2*n)) / denominator
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / denominator
+
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+func (c *TableDataComparison) addDifference(diff RowDifference, maxDifferences int) {
+	c.Mismatches++
+
+	if maxDifferences > 0 && len(c.Differences) >= maxDifferences {
+		return
+	}
+
+	c.Differences = append(c.Differences, diff)
+}
+
+// sampleFraction returns the fraction of rows to compare in the table.
+func sampleFraction(db *sql.DB, tableName string, options DataOptions) (float64, error) {
+	if options.SamplePercent > 0 {
+		return options.SamplePercent / 100, nil
+	}
+
+	stats, err := GetTableStats(db, tableName, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if stats.EstimatedRows <= 0 {
+		// The table has not been analyzed yet
+		stats, err = GetTableStats(db, tableName, true)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if stats.Rows() <= options.SampleRows {
+		return 1, nil
+	}
+
+	return float64(options.SampleRows) / float64(stats.Rows()), nil
+}
+
+// CompareTableData compares the rows of a table by primary key, merging both result sets
+// in key order so the tables never have to be held in memory.
+func CompareTableData(DB1 *sql.DB, DB2 *sql.DB, tableName string, columns []string, options DataOptions) (TableDataComparison, error) {
+	comparison := TableDataComparison{TableName: tableName, Columns: columns}
+
+	primaryKey, err := GetPrimaryKey(DB1, tableName)
+	if err != nil {
+		return comparison, err
+	}
+
+	if len(primaryKey) == 0 {
+		comparison.Skipped = "table has no primary key"
+		return comparison, nil
+	}
+	comparison.PrimaryKey = primaryKey
+
+	filter, err := options.Filters.TableFilter(DB1, DB2, tableName)
+	if err != nil {
+		return comparison, err
+	}
+	comparison.Filter = filter
+
+	conditions := []string{}
+	if filter != "" {
+		conditions = append(conditions, filter)
+	}
+
+	if options.SamplePercent > 0 || options.SampleRows > 0 {
+		fraction, err := sampleFraction(DB1, tableName, options)
+		if err != nil {
+			return comparison, err
+		}
+
+		if fraction < 1 {
+			conditions = append(conditions, samplePredicate(primaryKey, options.Seed, fraction))
+		}
+	}
+
+	query := dataQuery(tableName, columns, primaryKey, strings.Join(conditions, " AND "))
+
+	// Filters are user provided, read-only transactions guarantee they cannot modify any data
+	DB1Tx, err := DB1.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return comparison, err
+	}
+	defer DB1Tx.Rollback()
+
+	DB2Tx, err := DB2.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return comparison, err
+	}
+	defer DB2Tx.Rollback()
+
+	// Queries without arguments are sent with the simple protocol, which runs every statement in them.
+	// Prepared statements are limited to a single statement, so a filter cannot end the transaction.
+	DB1Stmt, err := DB1Tx.Prepare(query)
+	if err != nil {
+		return comparison, err
+	}
+	defer DB1Stmt.Close()
+
+	DB2Stmt, err := DB2Tx.Prepare(query)
+	if err != nil {
+		return comparison, err
+	}
+	defer DB2Stmt.Close()
+
+	DB1Rows, err := DB1Stmt.Query()
+	if err != nil {
+		return comparison, err
+	}
+	defer DB1Rows.Close()
+
+	DB2Rows, err := DB2Stmt.Query()
+	if err != nil {
+		return comparison, err
+	}
+	defer DB2Rows.Close()
+
+	DB1Key, DB1Row, DB1Ok, err := nextRow(DB1Rows, columns)
+	if err != nil {
+		return comparison, err
+	}
+
+	DB2Key, DB2Row, DB2Ok, err := nextRow(DB2Rows, columns)
+	if err != nil {
+		return comparison, err
+	}
+
+	for DB1Ok || DB2Ok {
+		comparison.RowsCompared++
+
+		switch {
+		case DB1Ok && (!DB2Ok || DB1Key < DB2Key):
+			// Row not found in database 2
+			comparison.addDifference(RowDifference{TableName: tableName, Key: DB1Key, DB1: DB1Row}, options.MaxDifferences)
+
+			DB1Key, DB1Row, DB1Ok, err = nextRow(DB1Rows, columns)
+		case DB2Ok && (!DB1Ok || DB2Key < DB1Key):
+			// Row not found in database 1
+			comparison.addDifference(RowDifference{TableName: tableName, Key: DB2Key, DB2: DB2Row}, options.MaxDifferences)
+
+			DB2Key, DB2Row, DB2Ok, err = nextRow(DB2Rows, columns)
+		default:
+			changed := changedColumns(tableName, columns, DB1Row, DB2Row, options.Comparators)
+			if len(changed) > 0 {
+				comparison.addDifference(RowDifference{TableName: tableName, Key: DB1Key, Columns: changed, DB1: DB1Row, DB2: DB2Row}, options.MaxDifferences)
+			}
+
+			DB1Key, DB1Row, DB1Ok, err = nextRow(DB1Rows, columns)
+			if err != nil {
+				return comparison, err
+			}
+
+			DB2Key, DB2Row, DB2Ok, err = nextRow(DB2Rows, columns)
+		}
+
+		if err != nil {
+			return comparison, err
+		}
+	}
+
+	if comparison.RowsCompared > 0 {
+		comparison.MismatchRate = float64(comparison.Mismatches) / float64(comparison.RowsCompared) * 100
+	}
+
+	low, high := wilsonInterval(comparison.Mismatches, comparison.RowsCompared)
+	comparison.RateLow, comparison.RateHigh = low*100, high*100
+
+	return comparison, nil
+}
+
+// CommonColumns returns the columns present in both tables, in the order of the first table.
+func CommonColumns(DB1Cols, DB2Cols map[string]ColumnData) []string {
+	columns := []string{}
+	for name := range DB1Cols {
+		if _, ok := DB2Cols[name]; ok {
+			columns = append(columns, name)
+		}
+	}
+
+	sort.Slice(columns, func(i, j int) bool {
+		return DB1Cols[columns[i]].OrdinalPosition < DB1Cols[columns[j]].OrdinalPosition
+	})
+
+	return columns
+}
+
+// CompareData compares the rows of the given tables using the columns present in both databases.
+func CompareData(DB1 *sql.DB, DB2 *sql.DB, DB1Tables, DB2Tables map[string]map[string]ColumnData, tables []string, options DataOptions) (DataComparison, error) {
+	comparison := DataComparison{Sample: "All rows", Seed: options.Seed, Tables: []TableDataComparison{}}
+
+	if options.SamplePercent > 0 {
+		comparison.Sample = fmt.Sprintf("%g%%", options.SamplePercent)
+	} else if options.SampleRows > 0 {
+		comparison.Sample = fmt.Sprintf("%d rows", options.SampleRows)
+	}
+
+	sort.Strings(tables)
+	for _, table := range tables {
+		columns := CommonColumns(DB1Tables[table], DB2Tables[table])
+
+		tableComparison, err := CompareTableData(DB1, DB2, table, columns, options)
+		if err != nil {
+			return comparison, fmt.Errorf("comparing data of table %s: %w", table, err)
+		}
+
+		comparison.Tables = append(comparison.Tables, tableComparison)
+	}
+
+	return comparison, nil
+}
@@ -0,0 +1,37 @@
+package internal
+
+import "testing"
+
+func TestParseDataSample(t *testing.T) {
+	tests := []struct {
+		value       string
+		wantPercent float64
+		wantRows    int64
+		wantErr     bool
+	}{
+		{value: "0.1%", wantPercent: 0.1},
+		{value: "100%", wantPercent: 100},
+		{value: "10000", wantRows: 10000},
+		{value: "0%", wantErr: true},
+		{value: "101%", wantErr: true},
+		{value: "-5%", wantErr: true},
+		{value: "abc%", wantErr: true},
+		{value: "0", wantErr: true},
+		{value: "-10", wantErr: true},
+		{value: "1.5", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			percent, rows, err := ParseDataSample(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDataSample(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+
+			if percent != tt.wantPercent || rows != tt.wantRows {
+				t.Errorf("ParseDataSample(%q) = %v, %v, want %v, %v", tt.value, percent, rows, tt.wantPercent, tt.wantRows)
+			}
+		})
+	}
+}
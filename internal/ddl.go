@@ -0,0 +1,855 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// DDLStatement is a statement of a generated migration. Warning is set when the statement
+// cannot fully reverse or apply the change, e.g. when data would be lost.
+type DDLStatement struct {
+	SQL     string
+	Warning string
+}
+
+// tableIndex is an index that does not back a constraint.
+type tableIndex struct {
+	definition string
+	columns    []string
+}
+
+// tableDefinition holds the data needed to recreate a table.
+type tableDefinition struct {
+	name        string
+	columns     []ColumnData
+	types       map[string]string
+	constraints []string
+	foreignKeys []string
+	// partitionKey is the PARTITION BY clause of partitioned tables
+	partitionKey string
+	// partitions are the CREATE TABLE ... PARTITION OF statements of the partitions
+	partitions []string
+	indexes    []tableIndex
+	// ownedSequences maps the serial columns to the sequence they own
+	ownedSequences map[string]string
+	// unsupported lists the objects of the table that are not recreated, such as triggers
+	unsupported []string
+}
+
+// sequencePattern matches the default of serial columns.
+var sequencePattern = regexp.MustCompile(`^nextval\('(.+)'::regclass\)$`)
+
+// columnSequence returns the sequence the default of the column takes its values from, "" when none.
+func columnSequence(col ColumnData) string {
+	match := sequencePattern.FindStringSubmatch(string(col.ColumnDefault))
+	if match == nil {
+		return ""
+	}
+
+	return strings.ReplaceAll(match[1], "''", "'")
+}
+
+func addWarning(statement *DDLStatement, warning string) {
+	if statement.Warning == "" {
+		statement.Warning = warning
+		return
+	}
+
+	statement.Warning += "; " + warning
+}
+
+func qualifiedTable(tableName string) string {
+	return Schema + "." + pq.QuoteIdentifier(tableName)
+}
+
+// columnDefinition returns the definition of the column as used in CREATE TABLE and ADD COLUMN.
+func columnDefinition(col ColumnData, typ string) string {
+	definition := fmt.Sprintf("%s %s", pq.QuoteIdentifier(col.ColumnName), typ)
+
+	if col.CollationName != "Null" {
+		definition += " COLLATE " + pq.QuoteIdentifier(string(col.CollationName))
+	}
+
+	switch {
+	case col.IsGenerated == "ALWAYS":
+		definition += fmt.Sprintf(" GENERATED ALWAYS AS (%s) STORED", col.GenerationExpression)
+	case col.IsIdentity == "YES":
+		definition += fmt.Sprintf(" GENERATED %s AS IDENTITY", col.IdentityGeneration)
+	case col.ColumnDefault != "Null":
+		definition += " DEFAULT " + string(col.ColumnDefault)
+	}
+
+	if col.IsNullable == "NO" {
+		definition += " NOT NULL"
+	}
+
+	return definition
+}
+
+func getConstraints(db Querier, tableName string) ([]string, []string, error) {
+	rows, err := db.Query(`SELECT
+		con.conname::text, con.contype, pg_get_constraintdef(con.oid)
+	FROM pg_constraint con
+	INNER JOIN pg_class c ON c.oid = con.conrelid
+	INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = 'public' AND c.relname = $1 AND con.contype IN ('p', 'u', 'c', 'f', 'x')
+	ORDER BY con.contype, con.conname`, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	constraints, foreignKeys := []string{}, []string{}
+	for rows.Next() {
+		var name, contype, definition string
+		if err = rows.Scan(&name, &contype, &definition); err != nil {
+			return nil, nil, err
+		}
+
+		constraint := fmt.Sprintf("CONSTRAINT %s %s", pq.QuoteIdentifier(name), definition)
+		if contype == "f" {
+			// Foreign keys are added once every table exists
+			foreignKeys = append(foreignKeys, fmt.Sprintf("ALTER TABLE %s ADD %s;", qualifiedTable(tableName), constraint))
+			continue
+		}
+
+		constraints = append(constraints, constraint)
+	}
+
+	return constraints, foreignKeys, rows.Err()
+}
+
+// getTablePartitions returns the PARTITION BY clause of the table and the statements that create its
+// partitions. Partitions that are partitioned themselves are returned in nested.
+func getTablePartitions(db Querier, tableName string) (string, []string, []string, error) {
+	var partitionKey sql.NullString
+	err := db.QueryRow(`SELECT pg_get_partkeydef($1::regclass)`, qualifiedTable(tableName)).Scan(&partitionKey)
+	if err != nil || !partitionKey.Valid {
+		return "", nil, nil, err
+	}
+
+	rows, err := db.Query(`SELECT c.relname::text, pg_get_expr(c.relpartbound, c.oid), c.relkind = 'p'
+	FROM pg_inherits i
+	INNER JOIN pg_class c ON c.oid = i.inhrelid
+	WHERE i.inhparent = $1::regclass
+	ORDER BY c.relname`, qualifiedTable(tableName))
+	if err != nil {
+		return "", nil, nil, err
+	}
+	defer rows.Close()
+
+	partitions, nested := []string{}, []string{}
+	for rows.Next() {
+		var name, bound string
+		var partitioned bool
+		if err = rows.Scan(&name, &bound, &partitioned); err != nil {
+			return "", nil, nil, err
+		}
+
+		partitions = append(partitions, fmt.Sprintf("CREATE TABLE %s PARTITION OF %s %s;", qualifiedTable(name), qualifiedTable(tableName), bound))
+		if partitioned {
+			nested = append(nested, "partitions of "+name)
+		}
+	}
+
+	return "PARTITION BY " + partitionKey.String, partitions, nested, rows.Err()
+}
+
+// getIndexes returns the indexes of the table that are not created by a constraint.
+func getIndexes(db Querier, tableName string) ([]tableIndex, error) {
+	rows, err := db.Query(`SELECT
+		pg_get_indexdef(i.indexrelid),
+		array(SELECT a.attname::text FROM pg_attribute a WHERE a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey) ORDER BY a.attnum)
+	FROM pg_index i
+	WHERE i.indrelid = $1::regclass
+		AND NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conrelid = i.indrelid AND con.conindid = i.indexrelid)
+	ORDER BY i.indexrelid::regclass::text`, qualifiedTable(tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	indexes := []tableIndex{}
+	for rows.Next() {
+		var index tableIndex
+		if err = rows.Scan(&index.definition, pq.Array(&index.columns)); err != nil {
+			return nil, err
+		}
+
+		// Indexes of partitioned tables are defined ON ONLY the parent, create them on the partitions too
+		index.definition = strings.Replace(index.definition, " ON ONLY ", " ON ", 1)
+		index.definition = strings.Replace(index.definition, " INDEX ", " INDEX IF NOT EXISTS ", 1) + ";"
+		indexes = append(indexes, index)
+	}
+
+	return indexes, rows.Err()
+}
+
+// getOwnedSequences returns the sequences owned by the columns of the table, as created for serial columns.
+func getOwnedSequences(db Querier, tableName string) (map[string]string, error) {
+	rows, err := db.Query(`SELECT a.attname::text, s.oid::regclass::text
+	FROM pg_depend d
+	INNER JOIN pg_class s ON s.oid = d.objid AND s.relkind = 'S'
+	INNER JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+	WHERE d.classid = 'pg_class'::regclass AND d.refobjid = $1::regclass AND d.deptype = 'a'`, qualifiedTable(tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sequences := map[string]string{}
+	for rows.Next() {
+		var column, sequence string
+		if err = rows.Scan(&column, &sequence); err != nil {
+			return nil, err
+		}
+
+		sequences[column] = sequence
+	}
+
+	return sequences, rows.Err()
+}
+
+// getUnsupportedObjects returns the triggers and policies of the table, which are not recreated.
+func getUnsupportedObjects(db Querier, tableName string) ([]string, error) {
+	rows, err := db.Query(`SELECT 'trigger ' || tgname FROM pg_trigger WHERE tgrelid = $1::regclass AND NOT tgisinternal
+	UNION ALL
+	SELECT 'policy ' || polname FROM pg_policy WHERE polrelid = $1::regclass
+	ORDER BY 1`, qualifiedTable(tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	objects := []string{}
+	for rows.Next() {
+		var object string
+		if err = rows.Scan(&object); err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, object)
+	}
+
+	return objects, rows.Err()
+}
+
+func getTableDefinition(db *sql.DB, tableName string, columns map[string]ColumnData) (tableDefinition, error) {
+	table := tableDefinition{name: tableName}
+
+	for _, col := range columns {
+		table.columns = append(table.columns, col)
+	}
+	sort.Slice(table.columns, func(i, j int) bool {
+		return table.columns[i].OrdinalPosition < table.columns[j].OrdinalPosition
+	})
+
+	var err error
+	table.types, err = GetColumnTypes(db, tableName)
+	if err != nil {
+		return table, err
+	}
+
+	table.constraints, table.foreignKeys, err = getConstraints(db, tableName)
+	if err != nil {
+		return table, err
+	}
+
+	var nested []string
+	table.partitionKey, table.partitions, nested, err = getTablePartitions(db, tableName)
+	if err != nil {
+		return table, err
+	}
+
+	table.indexes, err = getIndexes(db, tableName)
+	if err != nil {
+		return table, err
+	}
+
+	table.ownedSequences, err = getOwnedSequences(db, tableName)
+	if err != nil {
+		return table, err
+	}
+
+	table.unsupported, err = getUnsupportedObjects(db, tableName)
+	table.unsupported = append(table.unsupported, nested...)
+
+	return table, err
+}
+
+// sequenceStatements returns the statements that create the sequence used by the default of the column,
+// run before the column is created, and the statement that makes the column own it, run afterwards.
+// When restore is set the sequence was dropped with its column, so its current value is lost.
+func (t tableDefinition) sequenceStatements(col ColumnData, restore bool) ([]DDLStatement, []DDLStatement) {
+	sequence := columnSequence(col)
+	if sequence == "" {
+		return nil, nil
+	}
+
+	create := DDLStatement{SQL: fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s;", sequence)}
+	switch col.DataType {
+	case "smallint", "integer", "bigint":
+		create.SQL = fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s AS %s;", sequence, col.DataType)
+	}
+
+	if t.ownedSequences[col.ColumnName] != sequence {
+		return []DDLStatement{create}, nil
+	}
+
+	if restore {
+		create.Warning = fmt.Sprintf("IRREVERSIBLE: sequence %s restarts at its start value", sequence)
+	}
+
+	owned := DDLStatement{
+		SQL: fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.%s;", sequence, qualifiedTable(t.name), pq.QuoteIdentifier(col.ColumnName)),
+	}
+
+	return []DDLStatement{create}, []DDLStatement{owned}
+}
+
+// notRecreated returns the warning for the objects of the table that are not recreated.
+func (t tableDefinition) notRecreated(objects []string, restore bool) string {
+	if len(objects) == 0 {
+		return ""
+	}
+
+	if restore {
+		return fmt.Sprintf("IRREVERSIBLE: %s of table %s are not recreated", strings.Join(objects, ", "), t.name)
+	}
+
+	return fmt.Sprintf("%s of table %s are not created", strings.Join(objects, ", "), t.name)
+}
+
+// statements returns the statements that create the table with its sequences, partitions and indexes.
+// Foreign keys are not included since the referenced tables may not exist yet. When restore is set
+// the table is recreated after being dropped and the state that cannot be restored is flagged.
+// warnings are added to the CREATE TABLE statement.
+func (t tableDefinition) statements(restore bool, warnings ...string) []DDLStatement {
+	statements, owned := []DDLStatement{}, []DDLStatement{}
+	definitions := []string{}
+	for _, col := range t.columns {
+		create, own := t.sequenceStatements(col, restore)
+		statements = append(statements, create...)
+		owned = append(owned, own...)
+
+		definitions = append(definitions, "\t"+columnDefinition(col, t.types[col.ColumnName]))
+	}
+
+	for _, constraint := range t.constraints {
+		definitions = append(definitions, "\t"+constraint)
+	}
+
+	table := DDLStatement{SQL: fmt.Sprintf("CREATE TABLE %s (\n%s\n)", qualifiedTable(t.name), strings.Join(definitions, ",\n"))}
+	if t.partitionKey != "" {
+		table.SQL += " " + t.partitionKey
+	}
+	table.SQL += ";"
+	if warning := t.notRecreated(t.unsupported, restore); warning != "" {
+		warnings = append(warnings, warning)
+	}
+	for _, warning := range warnings {
+		addWarning(&table, warning)
+	}
+
+	statements = append(statements, table)
+	for _, partition := range t.partitions {
+		statements = append(statements, DDLStatement{SQL: partition})
+	}
+	statements = append(statements, owned...)
+	for _, index := range t.indexes {
+		statements = append(statements, DDLStatement{SQL: index.definition})
+	}
+
+	return statements
+}
+
+// addColumnStatements returns the statements that add the column to the table with the sequence used
+// by its default. Adding a NOT NULL column without a default fails when the table has rows. warnings
+// are added to the ADD COLUMN statement.
+func (t tableDefinition) addColumnStatements(col ColumnData, restore bool, warnings ...string) []DDLStatement {
+	statements, owned := t.sequenceStatements(col, restore)
+
+	statement := DDLStatement{
+		SQL: fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", qualifiedTable(t.name), columnDefinition(col, t.types[col.ColumnName])),
+	}
+	if col.IsNullable == "NO" && col.ColumnDefault == "Null" && col.IsIdentity != "YES" && col.IsGenerated != "ALWAYS" {
+		warnings = append(warnings, fmt.Sprintf("adding NOT NULL column %s.%s without a default fails when the table has rows", t.name, col.ColumnName))
+	}
+	for _, warning := range warnings {
+		addWarning(&statement, warning)
+	}
+
+	statements = append(statements, statement)
+
+	return append(statements, owned...)
+}
+
+// columnIndexes returns the indexes that include any of the columns.
+func (t tableDefinition) columnIndexes(columns []string) []DDLStatement {
+	statements := []DDLStatement{}
+	for _, index := range t.indexes {
+		for _, column := range index.columns {
+			if slices.Contains(columns, column) {
+				statements = append(statements, DDLStatement{SQL: index.definition})
+				break
+			}
+		}
+	}
+
+	return statements
+}
+
+// tableConstraint is a constraint of a table with its definition as returned by pg_get_constraintdef.
+type tableConstraint struct {
+	tableName  string
+	name       string
+	definition string
+}
+
+func (c tableConstraint) add() DDLStatement {
+	return DDLStatement{SQL: fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s;", qualifiedTable(c.tableName), pq.QuoteIdentifier(c.name), c.definition)}
+}
+
+func (c tableConstraint) drop() DDLStatement {
+	return DDLStatement{SQL: fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", qualifiedTable(c.tableName), pq.QuoteIdentifier(c.name))}
+}
+
+// getReferencingForeignKeys returns the foreign keys that reference any of the columns of the table,
+// or the table itself when columns is nil.
+func getReferencingForeignKeys(db Querier, tableName string, columns []string) ([]tableConstraint, error) {
+	rows, err := db.Query(`SELECT
+		c.relname::text, con.conname::text, pg_get_constraintdef(con.oid)
+	FROM pg_constraint con
+	INNER JOIN pg_class c ON c.oid = con.conrelid
+	INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE con.confrelid = $1::regclass AND con.contype = 'f' AND n.nspname = 'public' AND NOT c.relispartition
+		AND ($2::text[] IS NULL OR EXISTS (SELECT 1 FROM pg_attribute a
+			WHERE a.attrelid = con.confrelid AND a.attnum = ANY(con.confkey) AND a.attname = ANY($2::text[])))
+	ORDER BY c.relname, con.conname`, qualifiedTable(tableName), pq.Array(columns))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	foreignKeys := []tableConstraint{}
+	for rows.Next() {
+		var fk tableConstraint
+		if err = rows.Scan(&fk.tableName, &fk.name, &fk.definition); err != nil {
+			return nil, err
+		}
+
+		foreignKeys = append(foreignKeys, fk)
+	}
+
+	return foreignKeys, rows.Err()
+}
+
+// getColumnConstraints returns the constraints of the table that include any of the columns. Each
+// constraint is returned once, even when it includes several of the columns.
+func getColumnConstraints(db Querier, tableName string, columns []string) ([]tableConstraint, error) {
+	rows, err := db.Query(`SELECT
+		con.conname::text, pg_get_constraintdef(con.oid)
+	FROM pg_constraint con
+	WHERE con.conrelid = $1::regclass AND con.contype IN ('p', 'u', 'c', 'f', 'x')
+		AND EXISTS (SELECT 1 FROM pg_attribute a
+			WHERE a.attrelid = con.conrelid AND a.attnum = ANY(con.conkey) AND a.attname = ANY($2::text[]))
+	ORDER BY con.contype, con.conname`, qualifiedTable(tableName), pq.Array(columns))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	constraints := []tableConstraint{}
+	for rows.Next() {
+		constraint := tableConstraint{tableName: tableName}
+		if err = rows.Scan(&constraint.name, &constraint.definition); err != nil {
+			return nil, err
+		}
+
+		constraints = append(constraints, constraint)
+	}
+
+	return constraints, rows.Err()
+}
+
+// dropTableStatements returns the statements that drop the tables. The foreign keys that reference them
+// from other tables are dropped first, so the tables can be dropped in any order.
+func dropTableStatements(tables []string, foreignKeys []ForeignKey, ifExists bool) []DDLStatement {
+	statements := []DDLStatement{}
+
+	for _, fk := range foreignKeys {
+		if fk.TableName == fk.ReferencedTable || !slices.Contains(tables, fk.ReferencedTable) {
+			continue
+		}
+
+		statements = append(statements, DDLStatement{
+			SQL: fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", qualifiedTable(fk.TableName), pq.QuoteIdentifier(fk.Name)),
+		})
+	}
+
+	for _, tableName := range tables {
+		if ifExists {
+			statements = append(statements, DDLStatement{SQL: fmt.Sprintf("DROP TABLE IF EXISTS %s;", qualifiedTable(tableName))})
+			continue
+		}
+
+		statements = append(statements, DDLStatement{
+			SQL:     fmt.Sprintf("DROP TABLE %s;", qualifiedTable(tableName)),
+			Warning: fmt.Sprintf("dropping table %s deletes its data", tableName),
+		})
+	}
+
+	return statements
+}
+
+func hasRows(db *sql.DB, query string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(query).Scan(&exists)
+
+	return exists, err
+}
+
+// alterColumnStatements returns the statements that change the column from its current definition
+// to the target definition. typ is the full type of the target column.
+func alterColumnStatements(current, target ColumnData, typ string) []DDLStatement {
+	statements := []DDLStatement{}
+	prefix := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s", qualifiedTable(target.TableName), pq.QuoteIdentifier(target.ColumnName))
+
+	typeChanged := current.DataType != target.DataType || current.CharMaxLen != target.CharMaxLen ||
+		current.NumericPrecision != target.NumericPrecision || current.NumericScale != target.NumericScale ||
+		current.DatetimePrecision != target.DatetimePrecision || current.IntervalType != target.IntervalType ||
+		current.ElementType != target.ElementType || current.CollationName != target.CollationName
+
+	if typeChanged {
+		collation := ""
+		if target.CollationName != "Null" {
+			collation = " COLLATE " + pq.QuoteIdentifier(string(target.CollationName))
+		}
+
+		statements = append(statements, DDLStatement{
+			SQL:     fmt.Sprintf("%s TYPE %s%s USING %s::%s;", prefix, typ, collation, pq.QuoteIdentifier(target.ColumnName), typ),
+			Warning: "changing the column type may truncate or fail to convert existing values",
+		})
+	}
+
+	if current.IsIdentity != target.IsIdentity {
+		if target.IsIdentity == "YES" {
+			statements = append(statements, DDLStatement{SQL: fmt.Sprintf("%s ADD GENERATED %s AS IDENTITY;", prefix, target.IdentityGeneration)})
+		} else {
+			statements = append(statements, DDLStatement{SQL: fmt.Sprintf("%s DROP IDENTITY IF EXISTS;", prefix)})
+		}
+	} else if target.IsIdentity == "YES" && current.IdentityGeneration != target.IdentityGeneration {
+		statements = append(statements, DDLStatement{SQL: fmt.Sprintf("%s SET GENERATED %s;", prefix, target.IdentityGeneration)})
+	}
+
+	if current.IsGenerated != target.IsGenerated || current.GenerationExpression != target.GenerationExpression {
+		if target.IsGenerated == "ALWAYS" {
+			statements = append(statements, DDLStatement{
+				SQL:     fmt.Sprintf("-- %s: set generation expression to (%s)", prefix, target.GenerationExpression),
+				Warning: "generation expressions cannot be changed in place, the column has to be recreated manually",
+			})
+		} else {
+			statements = append(statements, DDLStatement{SQL: fmt.Sprintf("%s DROP EXPRESSION IF EXISTS;", prefix)})
+		}
+	}
+
+	if current.ColumnDefault != target.ColumnDefault && target.IsIdentity != "YES" {
+		if target.ColumnDefault == "Null" {
+			statements = append(statements, DDLStatement{SQL: fmt.Sprintf("%s DROP DEFAULT;", prefix)})
+		} else {
+			statements = append(statements, DDLStatement{SQL: fmt.Sprintf("%s SET DEFAULT %s;", prefix, target.ColumnDefault)})
+		}
+	}
+
+	if current.IsNullable != target.IsNullable {
+		if target.IsNullable == "NO" {
+			statements = append(statements, DDLStatement{SQL: fmt.Sprintf("%s SET NOT NULL;", prefix)})
+		} else {
+			statements = append(statements, DDLStatement{SQL: fmt.Sprintf("%s DROP NOT NULL;", prefix)})
+		}
+	}
+
+	return statements
+}
+
+// GenerateRollback returns the statements that restore the current definitions of the second database
+// (target) after it has been changed to match the first database (source). Changes that cannot be
+// reversed, such as dropped columns or tables that contain data, sequences that restart or triggers that
+// are not recreated, are flagged as IRREVERSIBLE.
+func GenerateRollback(DB1, DB2 *sql.DB, result ComparisonResult) ([]DDLStatement, error) {
+	statements := []DDLStatement{}
+	foreignKeys := []DDLStatement{}
+
+	DB2Tables, err := GetDBTableData(DB2)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tables only present in the target are dropped by the migration, recreate them
+	droppedTables := append([]string{}, result.MissingTablesInDB1...)
+	sort.Strings(droppedTables)
+	for _, tableName := range droppedTables {
+		table, err := getTableDefinition(DB2, tableName, DB2Tables[tableName])
+		if err != nil {
+			return nil, err
+		}
+
+		withData, err := hasRows(DB2, fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s)", qualifiedTable(tableName)))
+		if err != nil {
+			return nil, err
+		}
+
+		warnings := []string{}
+		if withData {
+			warnings = append(warnings, fmt.Sprintf("IRREVERSIBLE: table %s contains data that is lost when it is dropped", tableName))
+		}
+
+		statements = append(statements, table.statements(true, warnings...)...)
+		for _, fk := range table.foreignKeys {
+			foreignKeys = append(foreignKeys, DDLStatement{SQL: fk})
+		}
+
+		// The migration drops the foreign keys of the other tables that reference it
+		inbound, err := getReferencingForeignKeys(DB2, tableName, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, fk := range inbound {
+			if !slices.Contains(droppedTables, fk.tableName) {
+				foreignKeys = append(foreignKeys, fk.add())
+			}
+		}
+	}
+
+	added, modified, dropped := []DDLStatement{}, []DDLStatement{}, []DDLStatement{}
+	definitions := map[string]tableDefinition{}
+	droppedColumns := map[string][]string{}
+	// restored holds the constraints added back, since a self-referencing foreign key both includes and references the columns
+	restored := map[string]bool{}
+	tableNames := []string{}
+
+	for _, change := range result.ColumnChanges() {
+		tableName := change.Table
+
+		table, ok := definitions[tableName]
+		if !ok {
+			table, err = getTableDefinition(DB2, tableName, DB2Tables[tableName])
+			if err != nil {
+				return nil, err
+			}
+			definitions[tableName] = table
+			tableNames = append(tableNames, tableName)
+		}
+
+		switch change.Type {
+		case ChangeAdded:
+			// Column added by the migration, the foreign keys that reference it are dropped first
+			inbound, err := getReferencingForeignKeys(DB1, tableName, []string{change.Column})
+			if err != nil {
+				return nil, err
+			}
+			for _, fk := range inbound {
+				added = append(added, fk.drop())
+			}
+
+			added = append(added, DDLStatement{
+				SQL: fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", qualifiedTable(tableName), pq.QuoteIdentifier(change.Column)),
+			})
+		case ChangeRemoved:
+			// Column dropped by the migration, along with its indexes and constraints
+			withData, err := hasRows(DB2, fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE %s IS NOT NULL)", qualifiedTable(tableName), pq.QuoteIdentifier(change.Column)))
+			if err != nil {
+				return nil, err
+			}
+
+			warnings := []string{}
+			if withData {
+				warnings = append(warnings, fmt.Sprintf("IRREVERSIBLE: column %s.%s contains data that is lost when it is dropped", tableName, change.Column))
+			}
+
+			dropped = append(dropped, table.addColumnStatements(*change.Before, true, warnings...)...)
+			droppedColumns[tableName] = append(droppedColumns[tableName], change.Column)
+		default:
+			modified = append(modified, alterColumnStatements(*change.After, *change.Before, table.types[change.Column])...)
+		}
+	}
+
+	for _, tableName := range tableNames {
+		if len(droppedColumns[tableName]) == 0 {
+			continue
+		}
+
+		dropped = append(dropped, definitions[tableName].columnIndexes(droppedColumns[tableName])...)
+
+		// Constraints are restored once all the dropped columns of the table exist, along with the
+		// foreign keys that reference the columns, which the migration drops
+		constraints, err := getColumnConstraints(DB2, tableName, droppedColumns[tableName])
+		if err != nil {
+			return nil, err
+		}
+		inbound, err := getReferencingForeignKeys(DB2, tableName, droppedColumns[tableName])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, constraint := range append(constraints, inbound...) {
+			key := constraint.tableName + "." + constraint.name
+			if restored[key] || slices.Contains(droppedTables, constraint.tableName) {
+				continue
+			}
+
+			restored[key] = true
+			foreignKeys = append(foreignKeys, constraint.add())
+		}
+	}
+
+	statements = append(statements, dropped...)
+	statements = append(statements, modified...)
+	statements = append(statements, added...)
+	statements = append(statements, foreignKeys...)
+
+	// Tables only present in the source are created by the migration
+	DB1ForeignKeys, err := GetForeignKeys(DB1)
+	if err != nil {
+		return nil, err
+	}
+
+	createdTables := append([]string{}, result.MissingTablesInDB2...)
+	sort.Strings(createdTables)
+	statements = append(statements, dropTableStatements(createdTables, DB1ForeignKeys, true)...)
+
+	return statements, nil
+}
+
+// GenerateMigration returns the statements that change the second database (target) to match the
+// first database (source). Statements that drop data or may fail are flagged with a warning.
+func GenerateMigration(DB1, DB2 *sql.DB, result ComparisonResult) ([]DDLStatement, error) {
+	statements := []DDLStatement{}
+	foreignKeys := []DDLStatement{}
+
+	DB1Tables, err := GetDBTableData(DB1)
+	if err != nil {
+		return nil, err
+	}
+
+	createdTables := append([]string{}, result.MissingTablesInDB2...)
+	sort.Strings(createdTables)
+	for _, tableName := range createdTables {
+		table, err := getTableDefinition(DB1, tableName, DB1Tables[tableName])
+		if err != nil {
+			return nil, err
+		}
+
+		statements = append(statements, table.statements(false)...)
+		for _, fk := range table.foreignKeys {
+			foreignKeys = append(foreignKeys, DDLStatement{SQL: fk})
+		}
+	}
+
+	added, modified, dropped := []DDLStatement{}, []DDLStatement{}, []DDLStatement{}
+	definitions := map[string]tableDefinition{}
+	addedColumns := map[string][]string{}
+	tableNames := []string{}
+
+	for _, change := range result.ColumnChanges() {
+		tableName := change.Table
+
+		table, ok := definitions[tableName]
+		if !ok {
+			table, err = getTableDefinition(DB1, tableName, DB1Tables[tableName])
+			if err != nil {
+				return nil, err
+			}
+			definitions[tableName] = table
+			tableNames = append(tableNames, tableName)
+		}
+
+		switch change.Type {
+		case ChangeAdded:
+			added = append(added, table.addColumnStatements(*change.After, false)...)
+			addedColumns[tableName] = append(addedColumns[tableName], change.Column)
+		case ChangeRemoved:
+			// Foreign keys that reference the column prevent it from being dropped
+			inbound, err := getReferencingForeignKeys(DB2, tableName, []string{change.Column})
+			if err != nil {
+				return nil, err
+			}
+			for _, fk := range inbound {
+				dropped = append(dropped, fk.drop())
+			}
+
+			dropped = append(dropped, DDLStatement{
+				SQL:     fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", qualifiedTable(tableName), pq.QuoteIdentifier(change.Column)),
+				Warning: fmt.Sprintf("dropping column %s.%s deletes its data", tableName, change.Column),
+			})
+		default:
+			modified = append(modified, alterColumnStatements(*change.Before, *change.After, table.types[change.Column])...)
+		}
+	}
+
+	// Indexes and constraints on the added columns are created once every column of the table exists
+	for _, tableName := range tableNames {
+		if len(addedColumns[tableName]) == 0 {
+			continue
+		}
+
+		added = append(added, definitions[tableName].columnIndexes(addedColumns[tableName])...)
+
+		constraints, err := getColumnConstraints(DB1, tableName, addedColumns[tableName])
+		if err != nil {
+			return nil, err
+		}
+		for _, constraint := range constraints {
+			foreignKeys = append(foreignKeys, constraint.add())
+		}
+	}
+
+	statements = append(statements, added...)
+	statements = append(statements, modified...)
+	statements = append(statements, foreignKeys...)
+	statements = append(statements, dropped...)
+
+	DB2ForeignKeys, err := GetForeignKeys(DB2)
+	if err != nil {
+		return nil, err
+	}
+
+	droppedTables := append([]string{}, result.MissingTablesInDB1...)
+	sort.Strings(droppedTables)
+	statements = append(statements, dropTableStatements(droppedTables, DB2ForeignKeys, false)...)
+
+	return statements, nil
+}
+
+// RenderDDL returns the statements as a SQL script. Warnings are written as comments above their statement.
+func RenderDDL(statements []DDLStatement) string {
+	var sb strings.Builder
+
+	for i, statement := range statements {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		if statement.Warning != "" {
+			fmt.Fprintf(&sb, "-- WARNING: %s\n", statement.Warning)
+		}
+
+		fmt.Fprintf(&sb, "%s\n", statement.SQL)
+	}
+
+	return sb.String()
+}
+
+// WriteDDL writes the statements as a SQL script preceded by a title comment.
+func WriteDDL(w io.Writer, title string, statements []DDLStatement) error {
+	_, err := fmt.Fprintf(w, "-- %s generated by go-dbcompare\n\n%s", title, RenderDDL(statements))
+
+	return err
+}
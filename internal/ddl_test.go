@@ -0,0 +1,368 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColumnSequence(t *testing.T) {
+	tests := []struct {
+		name          string
+		columnDefault NullString
+		want          string
+	}{
+		{name: "serial", columnDefault: "nextval('orders_id_seq'::regclass)", want: "orders_id_seq"},
+		{name: "quoted name", columnDefault: `nextval('"Order''s_id_seq"'::regclass)`, want: `"Order's_id_seq"`},
+		{name: "constant default", columnDefault: "0", want: ""},
+		{name: "no default", columnDefault: "Null", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columnSequence(ColumnData{ColumnDefault: tt.columnDefault}); got != tt.want {
+				t.Errorf("columnSequence() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// testColumn returns a nullable column of the orders table without a default.
+func testColumn(name, dataType string, position int) ColumnData {
+	return ColumnData{
+		TableName: "orders", ColumnName: name, OrdinalPosition: position, DataType: NullString(dataType), ColumnDefault: "Null",
+		IsNullable: "YES", IntervalType: "Null", CollationName: "Null", ElementType: "Null", IsIdentity: "NO",
+		IdentityGeneration: "Null", IsGenerated: "NEVER", GenerationExpression: "Null",
+	}
+}
+
+// testTable returns the definition of an orders table with a serial primary key, an index and a trigger.
+func testTable() tableDefinition {
+	id := testColumn("id", "integer", 1)
+	id.ColumnDefault, id.IsNullable = "nextval('orders_id_seq'::regclass)", "NO"
+
+	return tableDefinition{
+		name:           "orders",
+		columns:        []ColumnData{id, testColumn("total", "numeric", 2)},
+		types:          map[string]string{"id": "integer", "total": "numeric(12,2)"},
+		constraints:    []string{`CONSTRAINT "orders_pkey" PRIMARY KEY (id)`},
+		indexes:        []tableIndex{{definition: "CREATE INDEX IF NOT EXISTS orders_total_idx ON public.orders USING btree (total);", columns: []string{"total"}}},
+		ownedSequences: map[string]string{"id": "orders_id_seq"},
+		unsupported:    []string{"trigger audit"},
+	}
+}
+
+func TestAlterColumnStatements(t *testing.T) {
+	column := func(change func(*ColumnData)) ColumnData {
+		col := testColumn("total", "integer", 2)
+		change(&col)
+		return col
+	}
+	prefix := `ALTER TABLE public."orders" ALTER COLUMN "total"`
+
+	tests := []struct {
+		name            string
+		current, target ColumnData
+		typ             string
+		want            []DDLStatement
+	}{
+		{
+			name:    "type",
+			current: column(func(c *ColumnData) {}),
+			target:  column(func(c *ColumnData) { c.DataType = "bigint" }),
+			typ:     "bigint",
+			want: []DDLStatement{{
+				SQL:     prefix + ` TYPE bigint USING "total"::bigint;`,
+				Warning: "changing the column type may truncate or fail to convert existing values",
+			}},
+		},
+		{
+			name:    "collation",
+			current: column(func(c *ColumnData) { c.DataType = "text" }),
+			target:  column(func(c *ColumnData) { c.DataType, c.CollationName = "text", "C" }),
+			typ:     "text",
+			want: []DDLStatement{{
+				SQL:     prefix + ` TYPE text COLLATE "C" USING "total"::text;`,
+				Warning: "changing the column type may truncate or fail to convert existing values",
+			}},
+		},
+		{
+			name:    "default and not null",
+			current: column(func(c *ColumnData) {}),
+			target:  column(func(c *ColumnData) { c.ColumnDefault, c.IsNullable = "0", "NO" }),
+			typ:     "integer",
+			want:    []DDLStatement{{SQL: prefix + " SET DEFAULT 0;"}, {SQL: prefix + " SET NOT NULL;"}},
+		},
+		{
+			name:    "reverted default and not null",
+			current: column(func(c *ColumnData) { c.ColumnDefault, c.IsNullable = "0", "NO" }),
+			target:  column(func(c *ColumnData) {}),
+			typ:     "integer",
+			want:    []DDLStatement{{SQL: prefix + " DROP DEFAULT;"}, {SQL: prefix + " DROP NOT NULL;"}},
+		},
+		{
+			name:    "identity",
+			current: column(func(c *ColumnData) {}),
+			target:  column(func(c *ColumnData) { c.IsIdentity, c.IdentityGeneration = "YES", "ALWAYS" }),
+			typ:     "integer",
+			want:    []DDLStatement{{SQL: prefix + " ADD GENERATED ALWAYS AS IDENTITY;"}},
+		},
+		{
+			name:    "reverted identity",
+			current: column(func(c *ColumnData) { c.IsIdentity, c.IdentityGeneration = "YES", "ALWAYS" }),
+			target:  column(func(c *ColumnData) {}),
+			typ:     "integer",
+			want:    []DDLStatement{{SQL: prefix + " DROP IDENTITY IF EXISTS;"}},
+		},
+		{
+			name:    "identity generation",
+			current: column(func(c *ColumnData) { c.IsIdentity, c.IdentityGeneration = "YES", "ALWAYS" }),
+			target:  column(func(c *ColumnData) { c.IsIdentity, c.IdentityGeneration = "YES", "BY DEFAULT" }),
+			typ:     "integer",
+			want:    []DDLStatement{{SQL: prefix + " SET GENERATED BY DEFAULT;"}},
+		},
+		{
+			name:    "generation expression",
+			current: column(func(c *ColumnData) {}),
+			target:  column(func(c *ColumnData) { c.IsGenerated, c.GenerationExpression = "ALWAYS", "(price * 2)" }),
+			typ:     "integer",
+			want: []DDLStatement{{
+				SQL:     "-- " + prefix + ": set generation expression to ((price * 2))",
+				Warning: "generation expressions cannot be changed in place, the column has to be recreated manually",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := alterColumnStatements(tt.current, tt.target, tt.typ); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("alterColumnStatements() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableDefinitionStatements(t *testing.T) {
+	createTable := "CREATE TABLE public.\"orders\" (\n" +
+		"\t\"id\" integer DEFAULT nextval('orders_id_seq'::regclass) NOT NULL,\n" +
+		"\t\"total\" numeric(12,2),\n" +
+		"\tCONSTRAINT \"orders_pkey\" PRIMARY KEY (id)\n" +
+		")"
+	owned := DDLStatement{SQL: `ALTER SEQUENCE orders_id_seq OWNED BY public."orders"."id";`}
+	index := DDLStatement{SQL: "CREATE INDEX IF NOT EXISTS orders_total_idx ON public.orders USING btree (total);"}
+
+	partitioned := testTable()
+	partitioned.partitionKey = "PARTITION BY RANGE (id)"
+	partitioned.partitions = []string{`CREATE TABLE public."orders_1" PARTITION OF public."orders" FOR VALUES FROM (1) TO (1000);`}
+	partitioned.indexes, partitioned.unsupported = nil, nil
+
+	tests := []struct {
+		name     string
+		table    tableDefinition
+		restore  bool
+		warnings []string
+		want     []DDLStatement
+	}{
+		{
+			name:     "recreated table",
+			table:    testTable(),
+			restore:  true,
+			warnings: []string{"IRREVERSIBLE: table orders contains data that is lost when it is dropped"},
+			want: []DDLStatement{
+				{SQL: "CREATE SEQUENCE IF NOT EXISTS orders_id_seq AS integer;", Warning: "IRREVERSIBLE: sequence orders_id_seq restarts at its start value"},
+				{SQL: createTable + ";", Warning: "IRREVERSIBLE: table orders contains data that is lost when it is dropped; IRREVERSIBLE: trigger audit of table orders are not recreated"},
+				owned,
+				index,
+			},
+		},
+		{
+			name:    "recreated partitioned table",
+			table:   partitioned,
+			restore: true,
+			want: []DDLStatement{
+				{SQL: "CREATE SEQUENCE IF NOT EXISTS orders_id_seq AS integer;", Warning: "IRREVERSIBLE: sequence orders_id_seq restarts at its start value"},
+				{SQL: createTable + " PARTITION BY RANGE (id);"},
+				{SQL: `CREATE TABLE public."orders_1" PARTITION OF public."orders" FOR VALUES FROM (1) TO (1000);`},
+				owned,
+			},
+		},
+		{
+			name:  "created table",
+			table: testTable(),
+			want: []DDLStatement{
+				{SQL: "CREATE SEQUENCE IF NOT EXISTS orders_id_seq AS integer;"},
+				{SQL: createTable + ";", Warning: "trigger audit of table orders are not created"},
+				owned,
+				index,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.table.statements(tt.restore, tt.warnings...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddColumnStatements(t *testing.T) {
+	table := testTable()
+	serial := table.columns[0]
+	status := testColumn("status", "text", 3)
+	status.IsNullable = "NO"
+	table.types["status"] = "text"
+
+	tests := []struct {
+		name     string
+		col      ColumnData
+		restore  bool
+		warnings []string
+		want     []DDLStatement
+	}{
+		{
+			name:     "restored serial column",
+			col:      serial,
+			restore:  true,
+			warnings: []string{"IRREVERSIBLE: column orders.id contains data that is lost when it is dropped"},
+			want: []DDLStatement{
+				{SQL: "CREATE SEQUENCE IF NOT EXISTS orders_id_seq AS integer;", Warning: "IRREVERSIBLE: sequence orders_id_seq restarts at its start value"},
+				{
+					SQL:     `ALTER TABLE public."orders" ADD COLUMN "id" integer DEFAULT nextval('orders_id_seq'::regclass) NOT NULL;`,
+					Warning: "IRREVERSIBLE: column orders.id contains data that is lost when it is dropped",
+				},
+				{SQL: `ALTER SEQUENCE orders_id_seq OWNED BY public."orders"."id";`},
+			},
+		},
+		{
+			name:    "restored nullable column",
+			col:     table.columns[1],
+			restore: true,
+			want:    []DDLStatement{{SQL: `ALTER TABLE public."orders" ADD COLUMN "total" numeric(12,2);`}},
+		},
+		{
+			name: "added not null column without a default",
+			col:  status,
+			want: []DDLStatement{{
+				SQL:     `ALTER TABLE public."orders" ADD COLUMN "status" text NOT NULL;`,
+				Warning: "adding NOT NULL column orders.status without a default fails when the table has rows",
+			}},
+		},
+		{
+			name: "added serial column",
+			col:  serial,
+			want: []DDLStatement{
+				{SQL: "CREATE SEQUENCE IF NOT EXISTS orders_id_seq AS integer;"},
+				{SQL: `ALTER TABLE public."orders" ADD COLUMN "id" integer DEFAULT nextval('orders_id_seq'::regclass) NOT NULL;`},
+				{SQL: `ALTER SEQUENCE orders_id_seq OWNED BY public."orders"."id";`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := table.addColumnStatements(tt.col, tt.restore, tt.warnings...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addColumnStatements() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropTableStatements(t *testing.T) {
+	foreignKeys := []ForeignKey{
+		{Name: "customers_parent_fkey", TableName: "customers", ReferencedTable: "customers"},
+		{Name: "orders_customer_fkey", TableName: "orders", ReferencedTable: "customers"},
+		{Name: "orders_product_fkey", TableName: "orders", ReferencedTable: "products"},
+	}
+
+	tests := []struct {
+		name     string
+		tables   []string
+		ifExists bool
+		want     []DDLStatement
+	}{
+		{
+			name:     "tables created by the migration",
+			tables:   []string{"customers"},
+			ifExists: true,
+			want: []DDLStatement{
+				{SQL: `ALTER TABLE public."orders" DROP CONSTRAINT IF EXISTS "orders_customer_fkey";`},
+				{SQL: `DROP TABLE IF EXISTS public."customers";`},
+			},
+		},
+		{
+			name:   "tables dropped by the migration",
+			tables: []string{"customers", "products"},
+			want: []DDLStatement{
+				{SQL: `ALTER TABLE public."orders" DROP CONSTRAINT IF EXISTS "orders_customer_fkey";`},
+				{SQL: `ALTER TABLE public."orders" DROP CONSTRAINT IF EXISTS "orders_product_fkey";`},
+				{SQL: `DROP TABLE public."customers";`, Warning: "dropping table customers deletes its data"},
+				{SQL: `DROP TABLE public."products";`, Warning: "dropping table products deletes its data"},
+			},
+		},
+		{
+			name:     "no tables",
+			tables:   []string{},
+			ifExists: true,
+			want:     []DDLStatement{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dropTableStatements(tt.tables, foreignKeys, tt.ifExists); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dropTableStatements() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableConstraint(t *testing.T) {
+	constraint := tableConstraint{tableName: "orders", name: "orders_customer_fkey", definition: "FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE"}
+
+	tests := []struct {
+		name string
+		got  DDLStatement
+		want string
+	}{
+		{name: "add", got: constraint.add(), want: `ALTER TABLE public."orders" ADD CONSTRAINT "orders_customer_fkey" FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE;`},
+		{name: "drop", got: constraint.drop(), want: `ALTER TABLE public."orders" DROP CONSTRAINT IF EXISTS "orders_customer_fkey";`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got.SQL != tt.want || tt.got.Warning != "" {
+				t.Errorf("%s() = %+v, want %q", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderDDL(t *testing.T) {
+	tests := []struct {
+		name       string
+		statements []DDLStatement
+		want       string
+	}{
+		{name: "no statements", statements: []DDLStatement{}, want: ""},
+		{
+			name: "warnings as comments",
+			statements: []DDLStatement{
+				{SQL: `ALTER TABLE public."orders" DROP COLUMN "note";`, Warning: "dropping column orders.note deletes its data"},
+				{SQL: `DROP TABLE IF EXISTS public."customers";`},
+			},
+			want: "-- WARNING: dropping column orders.note deletes its data\n" +
+				"ALTER TABLE public.\"orders\" DROP COLUMN \"note\";\n" +
+				"\n" +
+				"DROP TABLE IF EXISTS public.\"customers\";\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderDDL(tt.statements); got != tt.want {
+				t.Errorf("RenderDDL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package internal
+
+import (
+	"database/sql"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Change statuses used in the schema diagrams. Added objects only exist in the first database
+// and removed objects only in the second one.
+const (
+	StatusAdded     = "added"
+	StatusRemoved   = "removed"
+	StatusChanged   = "changed"
+	StatusUnchanged = "unchanged"
+)
+
+type ForeignKey struct {
+	Name              string
+	TableName         string
+	Columns           []string
+	ReferencedTable   string
+	ReferencedColumns []string
+}
+
+func (f ForeignKey) definition() string {
+	return strings.Join(f.Columns, ",") + "->" + f.ReferencedTable + "(" + strings.Join(f.ReferencedColumns, ",") + ")"
+}
+
+type GraphColumn struct {
+	Name     string
+	DataType string
+	Status   string
+}
+
+type GraphTable struct {
+	Name    string
+	Status  string
+	Columns []GraphColumn
+}
+
+type GraphEdge struct {
+	Name   string
+	From   string
+	To     string
+	Label  string
+	Status string
+}
+
+// SchemaGraph holds the tables involved in differences, the tables they reference and the
+// foreign keys between them.
+type SchemaGraph struct {
+	Tables []GraphTable
+	Edges  []GraphEdge
+}
+
+// GetForeignKeys returns the foreign keys between the tables of the public schema, sorted by table and name.
+func GetForeignKeys(db Querier) ([]ForeignKey, error) {
+	rows, err := db.Query(`SELECT
+		con.conname::text, c.relname::text, r.relname::text,
+		array(SELECT a.attname::text FROM unnest(con.conkey) WITH ORDINALITY k(attnum, n)
+			INNER JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum ORDER BY k.n),
+		array(SELECT a.attname::text FROM unnest(con.confkey) WITH ORDINALITY k(attnum, n)
+			INNER JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum ORDER BY k.n)
+	FROM pg_constraint con
+	INNER JOIN pg_class c ON c.oid = con.conrelid
+	INNER JOIN pg_class r ON r.oid = con.confrelid
+	INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+	INNER JOIN pg_namespace rn ON rn.oid = r.relnamespace
+	WHERE n.nspname = 'public' AND rn.nspname = 'public' AND con.contype = 'f' AND NOT c.relispartition
+	ORDER BY c.relname, con.conname`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	foreignKeys := []ForeignKey{}
+	for rows.Next() {
+		var fk ForeignKey
+		if err = rows.Scan(&fk.Name, &fk.TableName, &fk.ReferencedTable, pq.Array(&fk.Columns), pq.Array(&fk.ReferencedColumns)); err != nil {
+			return nil, err
+		}
+
+		foreignKeys = append(foreignKeys, fk)
+	}
+
+	return foreignKeys, rows.Err()
+}
+
+func sortedColumns(columns map[string]ColumnData) []ColumnData {
+	sorted := []ColumnData{}
+	for _, col := range columns {
+		sorted = append(sorted, col)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].OrdinalPosition < sorted[j].OrdinalPosition
+	})
+
+	return sorted
+}
+
+// BuildSchemaGraph returns the tables that differ between both databases with their columns and
+// foreign keys. Tables referenced by or referencing a changed table are included unchanged so every
+// edge has both ends. The columns are the ones loaded by the comparison.
+func BuildSchemaGraph(DB1, DB2 *sql.DB, result ComparisonResult) (SchemaGraph, error) {
+	DB1Tables, DB2Tables := result.DB1Tables, result.DB2Tables
+
+	DB1ForeignKeys, err := GetForeignKeys(DB1)
+	if err != nil {
+		return SchemaGraph{}, err
+	}
+
+	DB2ForeignKeys, err := GetForeignKeys(DB2)
+	if err != nil {
+		return SchemaGraph{}, err
+	}
+
+	tableStatus := map[string]string{}
+	columnStatus := map[string]map[string]string{}
+	for _, change := range result.Changes {
+		if change.Kind == ObjectTable {
+			tableStatus[change.Table] = StatusAdded
+			if change.Type == ChangeRemoved {
+				tableStatus[change.Table] = StatusRemoved
+			}
+			continue
+		}
+
+		if columnStatus[change.Table] == nil {
+			columnStatus[change.Table] = map[string]string{}
+		}
+
+		switch change.Type {
+		case ChangeAdded:
+			columnStatus[change.Table][change.Column] = StatusAdded
+		case ChangeRemoved:
+			columnStatus[change.Table][change.Column] = StatusRemoved
+		default:
+			columnStatus[change.Table][change.Column] = StatusChanged
+		}
+		tableStatus[change.Table] = StatusChanged
+	}
+
+	// Foreign keys are matched by table and constraint name
+	edges := map[string]*GraphEdge{}
+	keys := []string{}
+	addEdge := func(fk ForeignKey, status string) {
+		key := fk.TableName + "." + fk.Name
+		if edge, ok := edges[key]; ok {
+			edge.Status = status
+			return
+		}
+
+		edges[key] = &GraphEdge{
+			Name:   fk.Name,
+			From:   fk.TableName,
+			To:     fk.ReferencedTable,
+			Label:  strings.Join(fk.Columns, ", ") + " → " + strings.Join(fk.ReferencedColumns, ", "),
+			Status: status,
+		}
+		keys = append(keys, key)
+	}
+
+	DB2Definitions := map[string]string{}
+	for _, fk := range DB2ForeignKeys {
+		DB2Definitions[fk.TableName+"."+fk.Name] = fk.definition()
+	}
+
+	DB1Definitions := map[string]bool{}
+	for _, fk := range DB1ForeignKeys {
+		key := fk.TableName + "." + fk.Name
+		DB1Definitions[key] = true
+
+		definition, ok := DB2Definitions[key]
+		switch {
+		case !ok:
+			addEdge(fk, StatusAdded)
+		case definition != fk.definition():
+			addEdge(fk, StatusChanged)
+		default:
+			addEdge(fk, StatusUnchanged)
+		}
+	}
+
+	for _, fk := range DB2ForeignKeys {
+		if !DB1Definitions[fk.TableName+"."+fk.Name] {
+			addEdge(fk, StatusRemoved)
+		}
+	}
+
+	for _, edge := range edges {
+		if edge.Status != StatusUnchanged {
+			if _, ok := tableStatus[edge.From]; !ok {
+				tableStatus[edge.From] = StatusChanged
+			}
+		}
+	}
+
+	// Keep the edges touching a changed table and add the tables at their other end
+	graph := SchemaGraph{}
+	sort.Strings(keys)
+	for _, key := range keys {
+		edge := edges[key]
+		_, fromChanged := tableStatus[edge.From]
+		_, toChanged := tableStatus[edge.To]
+		if !fromChanged && !toChanged && edge.Status == StatusUnchanged {
+			continue
+		}
+
+		graph.Edges = append(graph.Edges, *edge)
+	}
+
+	for _, edge := range graph.Edges {
+		for _, tableName := range []string{edge.From, edge.To} {
+			if _, ok := tableStatus[tableName]; !ok {
+				tableStatus[tableName] = StatusUnchanged
+			}
+		}
+	}
+
+	tableNames := []string{}
+	for tableName := range tableStatus {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		table := GraphTable{Name: tableName, Status: tableStatus[tableName]}
+
+		DB1Columns, DB2Columns := DB1Tables[tableName], DB2Tables[tableName]
+		for _, col := range sortedColumns(DB1Columns) {
+			status := StatusUnchanged
+			if table.Status == StatusAdded {
+				status = StatusAdded
+			} else if s, ok := columnStatus[tableName][col.ColumnName]; ok {
+				status = s
+			}
+
+			table.Columns = append(table.Columns, GraphColumn{Name: col.ColumnName, DataType: string(col.DataType), Status: status})
+		}
+
+		for _, col := range sortedColumns(DB2Columns) {
+			if _, ok := DB1Columns[col.ColumnName]; ok {
+				continue
+			}
+
+			table.Columns = append(table.Columns, GraphColumn{Name: col.ColumnName, DataType: string(col.DataType), Status: StatusRemoved})
+		}
+
+		graph.Tables = append(graph.Tables, table)
+	}
+
+	return graph, nil
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// DataFilters are predicates applied identically on both databases during the row comparison.
+// The global filter is only applied to the tables that have the columns it references.
+type DataFilters struct {
+	Global string            `json:"global,omitempty"`
+	Tables map[string]string `json:"tables,omitempty"`
+}
+
+// stripQuoted removes the contents of string literals and quoted identifiers so only the
+// SQL outside of them is validated. Backslashes escape the next character in escape strings,
+// E'...', and are rejected anywhere else, since whether they escape a quote in a plain string
+// depends on the standard_conforming_strings setting of the server.
+func stripQuoted(filter string) (string, error) {
+	var sb strings.Builder
+	var quote byte
+	escapes, escaped := false, false
+
+	for i := 0; i < len(filter); i++ {
+		c := filter[i]
+
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '\'' && c == '\\':
+			if !escapes {
+				return "", fmt.Errorf("backslashes are only allowed in E'' strings")
+			}
+			escaped = true
+		case quote != 0 && c == quote:
+			quote = 0
+			sb.WriteByte(c)
+		case quote != 0:
+			continue
+		case c == '\\':
+			return "", fmt.Errorf("filter must not contain backslashes outside of strings")
+		case c == '\'':
+			// E'' strings, a doubled quote continues the same string
+			prefixed := i > 0 && (filter[i-1] == 'E' || filter[i-1] == 'e') && (i == 1 || !isIdentifierChar(filter[i-2]))
+			escapes = prefixed || (i > 0 && filter[i-1] == '\'' && escapes)
+			quote = c
+			sb.WriteByte(c)
+		case c == '"':
+			quote = c
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	if quote != 0 {
+		return "", fmt.Errorf("unterminated quote")
+	}
+
+	return sb.String(), nil
+}
+
+// ValidateFilter checks that the filter is a single expression. Whether it is valid for a table is
+// checked with EXPLAIN, and the row comparison runs inside read-only transactions so any write is
+// rejected by the server.
+func ValidateFilter(filter string) error {
+	stripped, err := stripQuoted(filter)
+	if err != nil {
+		return err
+	}
+
+	if strings.Contains(stripped, ";") {
+		return fmt.Errorf("filter must be a single expression")
+	}
+
+	if strings.Contains(stripped, "--") || strings.Contains(stripped, "/*") {
+		return fmt.Errorf("filter must not contain comments")
+	}
+
+	if strings.Contains(stripped, "$") {
+		return fmt.Errorf("filter must not contain dollar quoted strings or parameters")
+	}
+
+	depth := 0
+	for _, r := range stripped {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+
+		if depth < 0 {
+			break
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("filter has unbalanced parentheses")
+	}
+
+	return nil
+}
+
+func (f DataFilters) Validate() error {
+	if f.Global != "" {
+		if err := ValidateFilter(f.Global); err != nil {
+			return fmt.Errorf("invalid global data filter: %w", err)
+		}
+	}
+
+	for table, filter := range f.Tables {
+		if err := ValidateFilter(filter); err != nil {
+			return fmt.Errorf("invalid data filter for table %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// explainFilter checks the filter against the table without reading any rows. The query is prepared,
+// so the server rejects a filter that holds more than one statement.
+func explainFilter(db *sql.DB, tableName, filter string) error {
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(fmt.Sprintf("EXPLAIN SELECT 1 FROM %s WHERE %s", qualifiedTable(tableName), filter))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec()
+	return err
+}
+
+// TableFilter returns the predicate to apply to the table in both databases, or an empty string when it is not filtered.
+func (f DataFilters) TableFilter(DB1 *sql.DB, DB2 *sql.DB, tableName string) (string, error) {
+	filters := []string{}
+
+	if f.Global != "" {
+		applies := true
+		for _, db := range []*sql.DB{DB1, DB2} {
+			err := explainFilter(db, tableName, f.Global)
+
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "42703" {
+				// undefined_column: the table does not have the columns used by the global filter
+				applies = false
+				break
+			}
+
+			if err != nil {
+				return "", fmt.Errorf("invalid global data filter for table %s: %w", tableName, err)
+			}
+		}
+
+		if applies {
+			filters = append(filters, "("+f.Global+")")
+		}
+	}
+
+	if filter, ok := f.Tables[tableName]; ok && filter != "" {
+		for _, db := range []*sql.DB{DB1, DB2} {
+			if err := explainFilter(db, tableName, filter); err != nil {
+				return "", fmt.Errorf("invalid data filter for table %s: %w", tableName, err)
+			}
+		}
+
+		filters = append(filters, "("+filter+")")
+	}
+
+	return strings.Join(filters, " AND "), nil
+}
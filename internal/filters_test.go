@@ -0,0 +1,37 @@
+package internal
+
+import "testing"
+
+func TestValidateFilter(t *testing.T) {
+	tests := []struct {
+		filter  string
+		wantErr bool
+	}{
+		{filter: "created_at > now() - interval '30 days'"},
+		{filter: "status IN ('active', 'pending')"},
+		{filter: "\"set\" = 1 AND \"lock\" IS NULL"},
+		{filter: "note = 'a; b -- c /* d */ $1'"},
+		{filter: "(a = 1 OR b = 2) AND c = 3"},
+		{filter: "a = 1; DROP TABLE orders", wantErr: true},
+		{filter: "a = 1 -- comment", wantErr: true},
+		{filter: "a = 1 /* comment */", wantErr: true},
+		{filter: "a = $$text$$", wantErr: true},
+		{filter: "(a = 1", wantErr: true},
+		{filter: "a = 1)", wantErr: true},
+		{filter: "a = 'unterminated", wantErr: true},
+		{filter: `note = E'it\'s; fine'`},
+		{filter: `note = E'a\\' OR note = 'b'`},
+		{filter: `note = E'it''s \' ok'`},
+		{filter: `path = '\' OR 1 = 1; DROP TABLE orders; --'`, wantErr: true},
+		{filter: "E'\\' ' = 'x' ; COMMIT; DROP TABLE orders; --'", wantErr: true},
+		{filter: `a = 1 \g`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			if err := ValidateFilter(tt.filter); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateFilter(%q) error = %v, wantErr %v", tt.filter, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+package helpers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// sectionLine describes a row of a report section as "Header: value" pairs.
+func sectionLine(section reportSection, row []string) string {
+	values := make([]string, len(row))
+	for i, v := range row {
+		values[i] = fmt.Sprintf("%s: %s", section.Headers[i], v)
+	}
+
+	return fmt.Sprintf("[%s] %s", section.Name, strings.Join(values, ", "))
+}
+
+func newJUnitSuite(name string, cases map[string][]string, names []string) junitTestSuite {
+	suite := junitTestSuite{Name: name}
+
+	for _, caseName := range names {
+		testCase := junitTestCase{Name: caseName, ClassName: name}
+
+		if lines := cases[caseName]; len(lines) > 0 {
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d differences found", len(lines)),
+				Type:    "SchemaDifference",
+				Text:    strings.Join(lines, "\n"),
+			}
+			suite.Failures++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+	}
+
+	return suite
+}
+
+// SaveAsJUnit writes the comparison result as JUnit XML. Every table is a test case that fails
+// when any difference was found for it. Differences that do not belong to a table, such as
+// privileges or settings, are reported as one test case per kind in a separate suite.
+func SaveAsJUnit(result internal.ComparisonResult, DB1Name string, DB2Name string, output string) error {
+	tables := map[string][]string{}
+	for _, name := range result.CommonTables {
+		tables[name] = []string{}
+	}
+
+	for _, table := range tableDifferences(result) {
+		for _, column := range table.Columns {
+			switch column.Type {
+			case internal.ChangeAdded:
+				tables[table.TableName] = append(tables[table.TableName], fmt.Sprintf("[Column %s] only in %s", column.Column, DB1Name))
+			case internal.ChangeRemoved:
+				tables[table.TableName] = append(tables[table.TableName], fmt.Sprintf("[Column %s] only in %s", column.Column, DB2Name))
+			default:
+				for _, attr := range column.ChangedAttributes() {
+					tables[table.TableName] = append(tables[table.TableName], fmt.Sprintf("[Column %s] %s %s: %s -> %s", column.Column, column.Type, attr.Name, attr.DB1(), attr.DB2()))
+				}
+			}
+		}
+	}
+
+	objects := map[string][]string{}
+	objectNames := []string{}
+	for _, section := range reportSections(result, DB1Name, DB2Name) {
+		tableColumn := -1
+		for i, header := range section.Headers {
+			if header == "Table Name" {
+				tableColumn = i
+				break
+			}
+		}
+
+		if tableColumn == -1 {
+			objectNames = append(objectNames, section.Name)
+			objects[section.Name] = []string{}
+		}
+
+		for i, row := range section.Rows {
+			if !section.Flagged[i] {
+				continue
+			}
+
+			if tableColumn == -1 {
+				objects[section.Name] = append(objects[section.Name], sectionLine(section, row))
+			} else {
+				tables[row[tableColumn]] = append(tables[row[tableColumn]], sectionLine(section, row))
+			}
+		}
+	}
+
+	tableNames := []string{}
+	for name := range tables {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+
+	report := junitTestSuites{Name: fmt.Sprintf("Database comparison: %s vs %s", DB1Name, DB2Name)}
+	report.Suites = append(report.Suites, newJUnitSuite("tables", tables, tableNames))
+	if len(objectNames) > 0 {
+		report.Suites = append(report.Suites, newJUnitSuite("database", objects, objectNames))
+	}
+
+	for _, suite := range report.Suites {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err = file.WriteString(xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(file)
+	encoder.Indent("", "  ")
+	return encoder.Encode(report)
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifRules are the kinds of differences reported in the SARIF output with their default level.
+var sarifRules = []sarifRule{
+	{ID: "table-missing", ShortDescription: sarifMessage{"Table only exists in one of the databases"}, DefaultConfiguration: sarifConfiguration{"error"}},
+	{ID: "column-added", ShortDescription: sarifMessage{"Column only exists in the first database"}, DefaultConfiguration: sarifConfiguration{"error"}},
+	{ID: "column-removed", ShortDescription: sarifMessage{"Column only exists in the second database"}, DefaultConfiguration: sarifConfiguration{"error"}},
+	{ID: "column-modified", ShortDescription: sarifMessage{"Column definition differs"}, DefaultConfiguration: sarifConfiguration{"error"}},
+	{ID: "policy-changed", ShortDescription: sarifMessage{"Row level security or policy differs"}, DefaultConfiguration: sarifConfiguration{"error"}},
+	{ID: "partition-changed", ShortDescription: sarifMessage{"Partitioning differs"}, DefaultConfiguration: sarifConfiguration{"warning"}},
+	{ID: "comment-changed", ShortDescription: sarifMessage{"Comment differs"}, DefaultConfiguration: sarifConfiguration{"note"}},
+	{ID: "privilege-changed", ShortDescription: sarifMessage{"Privilege differs"}, DefaultConfiguration: sarifConfiguration{"warning"}},
+	{ID: "owner-changed", ShortDescription: sarifMessage{"Object owner differs"}, DefaultConfiguration: sarifConfiguration{"warning"}},
+	{ID: "role-changed", ShortDescription: sarifMessage{"Role attribute differs"}, DefaultConfiguration: sarifConfiguration{"warning"}},
+	{ID: "table-stats-drift", ShortDescription: sarifMessage{"Row count or size differs above the threshold"}, DefaultConfiguration: sarifConfiguration{"warning"}},
+	{ID: "column-profile-drift", ShortDescription: sarifMessage{"Column profile differs above the tolerance"}, DefaultConfiguration: sarifConfiguration{"note"}},
+	{ID: "row-mismatch", ShortDescription: sarifMessage{"Row differs or only exists in one of the databases"}, DefaultConfiguration: sarifConfiguration{"warning"}},
+	{ID: "setting-changed", ShortDescription: sarifMessage{"Server setting differs"}, DefaultConfiguration: sarifConfiguration{"note"}},
+}
+
+func severityLevel(severity internal.Severity) string {
+	switch severity {
+	case internal.SeverityHigh:
+		return "error"
+	case internal.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifArtifact returns the file a difference is reported on. Databases have no source files, so
+// code scanning gets a file per table, schema/<table>.sql, and one for the roles and the settings.
+func sarifArtifact(name, kind string) string {
+	switch kind {
+	case "role":
+		return "schema/roles.sql"
+	case "setting":
+		return "schema/settings.sql"
+	}
+
+	table := strings.TrimPrefix(name, internal.Schema+".")
+	if i := strings.Index(table, "."); i != -1 {
+		table = table[:i]
+	}
+
+	return fmt.Sprintf("schema/%s.sql", table)
+}
+
+func newSARIFResult(ruleID, level, message, name, kind string) sarifResult {
+	ruleIndex := slices.IndexFunc(sarifRules, func(rule sarifRule) bool {
+		return rule.ID == ruleID
+	})
+
+	return sarifResult{
+		RuleID:    ruleID,
+		RuleIndex: ruleIndex,
+		Level:     level,
+		Message:   sarifMessage{message},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: sarifArtifact(name, kind)},
+				Region:           sarifRegion{StartLine: 1},
+			},
+			LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: name, Kind: kind}},
+		}},
+	}
+}
+
+// SaveAsSARIF writes the comparison result as a SARIF log with one result per difference.
+func SaveAsSARIF(result internal.ComparisonResult, DB1Name string, DB2Name string, output string) error {
+	results := []sarifResult{}
+
+	for _, change := range result.Changes {
+		present, absent := DB1Name, DB2Name
+		if change.Type == internal.ChangeRemoved {
+			present, absent = DB2Name, DB1Name
+		}
+
+		if change.Kind == internal.ObjectTable {
+			results = append(results, newSARIFResult("table-missing", "error", fmt.Sprintf("Table %s exists in %s but not in %s", change.Table, present, absent), change.Name, "table"))
+			continue
+		}
+
+		switch change.Type {
+		case internal.ChangeAdded:
+			results = append(results, newSARIFResult("column-added", "error", fmt.Sprintf("Column %s exists in %s but not in %s", change.Name, present, absent), change.Name, "column"))
+		case internal.ChangeRemoved:
+			results = append(results, newSARIFResult("column-removed", "error", fmt.Sprintf("Column %s exists in %s but not in %s", change.Name, present, absent), change.Name, "column"))
+		default:
+			changes := []string{}
+			for _, attr := range change.ChangedAttributes() {
+				changes = append(changes, fmt.Sprintf("%s: %s (%s) -> %s (%s)", attr.Name, attr.DB1(), DB1Name, attr.DB2(), DB2Name))
+			}
+			results = append(results, newSARIFResult("column-modified", "error", fmt.Sprintf("Column %s differs. %s", change.Name, strings.Join(changes, "; ")), change.Name, "column"))
+		}
+	}
+
+	for _, v := range result.PolicyDifferences {
+		name := internal.QualifiedName(v.TableName)
+		if v.PolicyName != "" {
+			name += "." + v.PolicyName
+		}
+		results = append(results, newSARIFResult("policy-changed", severityLevel(v.Severity), fmt.Sprintf("%s of %s differs: %s (%s) -> %s (%s)", v.Attribute, name, v.DB1, DB1Name, v.DB2, DB2Name), name, "policy"))
+	}
+
+	for _, v := range result.PartitionDiffs {
+		results = append(results, newSARIFResult("partition-changed", "warning", fmt.Sprintf("%s of %s differs: %s (%s) -> %s (%s)", v.Attribute, v.TableName, v.DB1, DB1Name, v.DB2, DB2Name), internal.QualifiedName(v.TableName), "table"))
+	}
+
+	for _, v := range result.CommentDifferences {
+		results = append(results, newSARIFResult("comment-changed", severityLevel(v.Severity), fmt.Sprintf("Comment of %s %s differs: %s (%s) -> %s (%s)", strings.ToLower(v.ObjectType), v.ObjectName, v.DB1, DB1Name, v.DB2, DB2Name), internal.QualifiedName(v.ObjectName), strings.ToLower(v.ObjectType)))
+	}
+
+	if result.Privileges != nil {
+		for _, v := range result.Privileges.Privileges {
+			results = append(results, newSARIFResult("privilege-changed", "warning", fmt.Sprintf("%s on %s %s for %s differs: %s (%s) -> %s (%s)", v.Privilege, strings.ToLower(v.ObjectType), v.ObjectName, v.Grantee, v.DB1, DB1Name, v.DB2, DB2Name), v.ObjectName, strings.ToLower(v.ObjectType)))
+		}
+		for _, v := range result.Privileges.Owners {
+			results = append(results, newSARIFResult("owner-changed", "warning", fmt.Sprintf("Owner of %s %s differs: %s (%s) -> %s (%s)", strings.ToLower(v.ObjectType), v.ObjectName, v.DB1, DB1Name, v.DB2, DB2Name), v.ObjectName, strings.ToLower(v.ObjectType)))
+		}
+		for _, v := range result.Privileges.Roles {
+			results = append(results, newSARIFResult("role-changed", "warning", fmt.Sprintf("%s of role %s differs: %s (%s) -> %s (%s)", v.Attribute, v.RoleName, v.DB1, DB1Name, v.DB2, DB2Name), v.RoleName, "role"))
+		}
+	}
+
+	for _, v := range result.TableStats {
+		if !v.Flagged {
+			continue
+		}
+		results = append(results, newSARIFResult("table-stats-drift", "warning", fmt.Sprintf("Table %s differs by %.2f%% in rows and %.2f%% in size", v.TableName, v.RowDiffPercent, v.SizeDiffPercent), internal.QualifiedName(v.TableName), "table"))
+	}
+
+	for _, v := range result.ProfileDifferences {
+		name := internal.QualifiedName(v.TableName, v.ColumnName)
+		results = append(results, newSARIFResult("column-profile-drift", "note", fmt.Sprintf("%s of %s differs: %s (%s) -> %s (%s)", v.Metric, name, v.DB1, DB1Name, v.DB2, DB2Name), name, "column"))
+	}
+
+	if result.Data != nil {
+		for _, table := range result.Data.Tables {
+			for _, diff := range table.Differences {
+				message := fmt.Sprintf("Row %s of %s differs in %s", diff.Key, diff.TableName, strings.Join(diff.Columns, ", "))
+				switch {
+				case diff.DB2 == nil:
+					message = fmt.Sprintf("Row %s of %s only exists in %s", diff.Key, diff.TableName, DB1Name)
+				case diff.DB1 == nil:
+					message = fmt.Sprintf("Row %s of %s only exists in %s", diff.Key, diff.TableName, DB2Name)
+				}
+				results = append(results, newSARIFResult("row-mismatch", "warning", message, internal.QualifiedName(diff.TableName), "table"))
+			}
+		}
+	}
+
+	if result.Settings != nil {
+		for _, v := range result.Settings.Differences {
+			results = append(results, newSARIFResult("setting-changed", "note", fmt.Sprintf("Setting %s differs: %s (%s) -> %s (%s)", v.Name, v.DB1, DB1Name, v.DB2, DB2Name), v.Name, "setting"))
+		}
+	}
+
+	report := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "go-dbcompare",
+				InformationURI: "https://github.com/CDavidSV/go-dbcompare",
+				Rules:          sarifRules,
+			}},
+			Results: results,
+		}},
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(output, data, 0o644)
+}
@@ -0,0 +1,159 @@
+package helpers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+func TestSarifArtifact(t *testing.T) {
+	tests := []struct {
+		name string
+		kind string
+		want string
+	}{
+		{name: "public.orders", kind: "table", want: "schema/orders.sql"},
+		{name: "public.orders.total", kind: "column", want: "schema/orders.sql"},
+		{name: "orders.own_rows", kind: "policy", want: "schema/orders.sql"},
+		{name: "reader", kind: "role", want: "schema/roles.sql"},
+		{name: "work_mem", kind: "setting", want: "schema/settings.sql"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sarifArtifact(tt.name, tt.kind); got != tt.want {
+				t.Errorf("sarifArtifact(%q, %q) = %q, want %q", tt.name, tt.kind, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveAsJUnit(t *testing.T) {
+	withSettings := testResult()
+	withSettings.Settings = &internal.SettingsComparison{Differences: []internal.SettingDifference{
+		{Name: "work_mem", Category: "Resource Usage", DB1: "4MB", DB2: "64MB", Change: internal.ChangeModified},
+	}}
+
+	tests := []struct {
+		name   string
+		result internal.ComparisonResult
+		// cases maps the suites to their test cases and whether each one fails
+		cases map[string]map[string]bool
+	}{
+		{
+			name:   "tables",
+			result: testResult(),
+			cases:  map[string]map[string]bool{"tables": {"customers": true, "invoices": true, "orders": true}},
+		},
+		{
+			name:   "database objects",
+			result: withSettings,
+			cases: map[string]map[string]bool{
+				"tables":   {"customers": true, "invoices": true, "orders": true},
+				"database": {"Settings": true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "result.xml")
+			if err := SaveAsJUnit(tt.result, "DB1", "DB2", output); err != nil {
+				t.Fatalf("SaveAsJUnit() error = %v", err)
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var report junitTestSuites
+			if err = xml.Unmarshal(data, &report); err != nil {
+				t.Fatalf("SaveAsJUnit() wrote invalid XML: %v", err)
+			}
+
+			got := map[string]map[string]bool{}
+			failures := 0
+			for _, suite := range report.Suites {
+				got[suite.Name] = map[string]bool{}
+				for _, testCase := range suite.TestCases {
+					got[suite.Name][testCase.Name] = testCase.Failure != nil
+					if testCase.Failure != nil {
+						failures++
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.cases) {
+				t.Errorf("SaveAsJUnit() cases = %v, want %v", got, tt.cases)
+			}
+			if report.Failures != failures {
+				t.Errorf("SaveAsJUnit() failures = %d, want %d", report.Failures, failures)
+			}
+		})
+	}
+}
+
+func TestSaveAsSARIF(t *testing.T) {
+	withSettings := testResult()
+	withSettings.Settings = &internal.SettingsComparison{Differences: []internal.SettingDifference{
+		{Name: "work_mem", Category: "Resource Usage", DB1: "4MB", DB2: "64MB", Change: internal.ChangeModified},
+	}}
+
+	tests := []struct {
+		name      string
+		result    internal.ComparisonResult
+		rules     []string
+		artifacts []string
+	}{
+		{
+			name:      "schema differences",
+			result:    testResult(),
+			rules:     []string{"table-missing", "table-missing", "column-modified", "column-added", "column-removed"},
+			artifacts: []string{"schema/customers.sql", "schema/invoices.sql", "schema/orders.sql", "schema/orders.sql", "schema/orders.sql"},
+		},
+		{
+			name:      "settings",
+			result:    withSettings,
+			rules:     []string{"table-missing", "table-missing", "column-modified", "column-added", "column-removed", "setting-changed"},
+			artifacts: []string{"schema/customers.sql", "schema/invoices.sql", "schema/orders.sql", "schema/orders.sql", "schema/orders.sql", "schema/settings.sql"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "result.sarif")
+			if err := SaveAsSARIF(tt.result, "DB1", "DB2", output); err != nil {
+				t.Fatalf("SaveAsSARIF() error = %v", err)
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var report sarifLog
+			if err = json.Unmarshal(data, &report); err != nil {
+				t.Fatalf("SaveAsSARIF() wrote invalid JSON: %v", err)
+			}
+
+			rules, artifacts := []string{}, []string{}
+			for _, result := range report.Runs[0].Results {
+				if sarifRules[result.RuleIndex].ID != result.RuleID {
+					t.Errorf("result %s has rule index %d", result.RuleID, result.RuleIndex)
+				}
+				rules = append(rules, result.RuleID)
+				artifacts = append(artifacts, result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+			}
+
+			if !reflect.DeepEqual(rules, tt.rules) || !reflect.DeepEqual(artifacts, tt.artifacts) {
+				t.Errorf("SaveAsSARIF() results = %q %q, want %q %q", rules, artifacts, tt.rules, tt.artifacts)
+			}
+		})
+	}
+}
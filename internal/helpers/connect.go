@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+	"github.com/CDavidSV/go-dbcompare/internal/config"
+	"github.com/briandowns/spinner"
+)
+
+// LoadConnections returns the configuration and the data source names of both databases. The DSNs
+// given as flags take precedence over the connection details of the configuration file, which is
+// still read for its data filters and comparators. The file is optional when both DSNs are given.
+func LoadConnections(configPath, dsn1, dsn2 string) (internal.Configuration, string, string, error) {
+	conf := internal.Configuration{
+		DB1: internal.DBConfig{
+			Name: "DB1",
+		},
+		DB2: internal.DBConfig{
+			Name: "DB2",
+		},
+	}
+
+	if dsn1 != "" && dsn2 != "" {
+		if _, err := os.Stat(configPath); errors.Is(err, fs.ErrNotExist) {
+			return conf, dsn1, dsn2, nil
+		}
+	}
+
+	conf, err := LoadConfigurationFile(configPath)
+	if err != nil {
+		return conf, "", "", err
+	}
+
+	if dsn1 == "" {
+		dsn1 = GetDataSourceName("postgres", conf.DB1.Username, conf.DB1.Password, conf.DB1.HostName, conf.DB1.Database, conf.DB1.Port, conf.DB1.Params)
+	}
+	if dsn2 == "" {
+		dsn2 = GetDataSourceName("postgres", conf.DB2.Username, conf.DB2.Password, conf.DB2.HostName, conf.DB2.Database, conf.DB2.Port, conf.DB2.Params)
+	}
+
+	return conf, dsn1, dsn2, nil
+}
+
+// ConnectWithSpinner connects to the database showing a spinner until the connection is established.
+func ConnectWithSpinner(name, dsn string) (*sql.DB, error) {
+	SaveCursorPosition()
+
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriterFile(os.Stderr))
+	s.Suffix = fmt.Sprintf(config.InfoStyle.Render(" Connecting to %s"), name)
+	s.Start()
+
+	db, err := ConnectDB("postgres", dsn)
+	s.Stop()
+	ClearLine()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, config.SuccessStyle.Render("Connected to %s\n"), name)
+
+	return db, nil
+}
@@ -0,0 +1,36 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"os"
+	"strings"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+func writeCSV(output string, records [][]string) error {
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err = writer.WriteAll(records); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// SaveAsCSV writes the differences to the output file, one row per difference, and the tables and
+// columns missing in one of the databases to a second file with a "_missing" suffix.
+func SaveAsCSV(result internal.ComparisonResult, DB1Name string, DB2Name string, output string) error {
+	differences, missing := internal.GenerateCSVData(result, DB1Name, DB2Name)
+
+	if err := writeCSV(output, differences); err != nil {
+		return err
+	}
+
+	return writeCSV(strings.TrimSuffix(output, ".csv")+"_missing.csv", missing)
+}
@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("%s is not a valid CSV: %v", path, err)
+	}
+
+	return records
+}
+
+func TestSaveAsCSV(t *testing.T) {
+	missing := [][]string{
+		internal.MissingCSVHeaders,
+		{"Table", "public", "customers", "", "DB1", "DB2"},
+		{"Table", "public", "invoices", "", "DB2", "DB1"},
+		{"Column", "public", "orders", "note", "DB1", "DB2"},
+		{"Column", "public", "orders", "status", "DB2", "DB1"},
+	}
+	columns := [][]string{
+		internal.CSVHeaders,
+		{"Column", "public", "orders", "total", "", "Data Type", "bigint", "integer", "modified"},
+		{"Column", "public", "orders", "note", "", "Data Type", "integer", "", "added"},
+		{"Column", "public", "orders", "status", "", "Data Type", "", "integer", "removed"},
+	}
+
+	withSettings := testResult()
+	withSettings.Settings = &internal.SettingsComparison{Differences: []internal.SettingDifference{
+		{Name: "work_mem", Category: "Resource Usage", DB1: "4MB", DB2: "64MB", Change: internal.ChangeModified},
+	}}
+
+	withData := testResult()
+	withData.Data = &internal.DataComparison{Tables: []internal.TableDataComparison{{
+		TableName: "orders",
+		Columns:   []string{"id", "total"},
+		Differences: []internal.RowDifference{
+			{
+				TableName: "orders", Key: "1", Columns: []string{"total"},
+				DB1: internal.Row{"id": {String: "1", Valid: true}, "total": {String: "10", Valid: true}},
+				DB2: internal.Row{"id": {String: "1", Valid: true}, "total": {}},
+			},
+			{
+				TableName: "orders", Key: "2",
+				DB1: internal.Row{"id": {String: "2", Valid: true}, "total": {String: "5", Valid: true}},
+			},
+		},
+	}}}
+
+	tests := []struct {
+		name        string
+		result      internal.ComparisonResult
+		differences [][]string
+	}{
+		{
+			name:        "columns",
+			result:      testResult(),
+			differences: columns,
+		},
+		{
+			name:   "settings",
+			result: withSettings,
+			differences: append(columns[:len(columns):len(columns)],
+				[]string{"Setting", "", "", "", "", "work_mem", "4MB", "64MB", "modified"}),
+		},
+		{
+			name:   "rows",
+			result: withData,
+			differences: append(columns[:len(columns):len(columns)],
+				[]string{"Row", "public", "orders", "total", "1", "Value", "10", "Null", "modified"},
+				[]string{"Row", "public", "orders", "", "2", "Row", "id=2, total=5", "", "added"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := SaveAsCSV(tt.result, "DB1", "DB2", filepath.Join(dir, "result.csv")); err != nil {
+				t.Fatalf("SaveAsCSV() error = %v", err)
+			}
+
+			if got := readCSV(t, filepath.Join(dir, "result.csv")); !reflect.DeepEqual(got, tt.differences) {
+				t.Errorf("SaveAsCSV() differences = %q, want %q", got, tt.differences)
+			}
+			if got := readCSV(t, filepath.Join(dir, "result_missing.csv")); !reflect.DeepEqual(got, missing) {
+				t.Errorf("SaveAsCSV() missing = %q, want %q", got, missing)
+			}
+		})
+	}
+}
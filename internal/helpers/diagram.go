@@ -0,0 +1,125 @@
+package helpers
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+// diagramColors are the fill and line colors used for each change status.
+var diagramColors = map[string]struct{ Fill, Line string }{
+	internal.StatusAdded:     {"#d9ead3", "#2e7d32"},
+	internal.StatusRemoved:   {"#f4cccc", "#c62828"},
+	internal.StatusChanged:   {"#fff2cc", "#f9a825"},
+	internal.StatusUnchanged: {"#ffffff", "#555555"},
+}
+
+// statusMarker prefixes the columns so the status is visible without colors.
+func statusMarker(status string) string {
+	switch status {
+	case internal.StatusAdded:
+		return "+ "
+	case internal.StatusRemoved:
+		return "- "
+	case internal.StatusChanged:
+		return "~ "
+	default:
+		return ""
+	}
+}
+
+// GenerateDOT returns the schema graph as a Graphviz digraph.
+func GenerateDOT(graph internal.SchemaGraph) string {
+	var sb strings.Builder
+
+	sb.WriteString("digraph schema {\n")
+	sb.WriteString("\trankdir=LR;\n")
+	sb.WriteString("\tnode [shape=plain, fontname=\"Helvetica\"];\n")
+	sb.WriteString("\tedge [fontname=\"Helvetica\", fontsize=10];\n\n")
+
+	for _, table := range graph.Tables {
+		colors := diagramColors[table.Status]
+
+		fmt.Fprintf(&sb, "\t%q [label=<\n\t\t<table border=\"1\" cellborder=\"0\" cellspacing=\"0\" cellpadding=\"4\" color=%q>\n", table.Name, colors.Line)
+		fmt.Fprintf(&sb, "\t\t\t<tr><td bgcolor=%q colspan=\"2\"><b>%s</b></td></tr>\n", colors.Fill, html.EscapeString(statusMarker(table.Status)+table.Name))
+
+		for _, column := range table.Columns {
+			columnColors := diagramColors[column.Status]
+			fmt.Fprintf(&sb, "\t\t\t<tr><td bgcolor=%q align=\"left\">%s</td><td bgcolor=%q align=\"left\">%s</td></tr>\n",
+				columnColors.Fill, html.EscapeString(statusMarker(column.Status)+column.Name), columnColors.Fill, html.EscapeString(column.DataType))
+		}
+
+		sb.WriteString("\t\t</table>\n\t>];\n")
+	}
+
+	if len(graph.Edges) > 0 {
+		sb.WriteString("\n")
+	}
+
+	for _, edge := range graph.Edges {
+		colors := diagramColors[edge.Status]
+		style := "solid"
+		if edge.Status == internal.StatusRemoved {
+			style = "dashed"
+		}
+
+		fmt.Fprintf(&sb, "\t%q -> %q [label=%q, color=%q, fontcolor=%q, style=%s];\n", edge.From, edge.To, edge.Label, colors.Line, colors.Line, style)
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+func mermaidText(text string) string {
+	return strings.NewReplacer("\"", "#quot;", "<", "#lt;", ">", "#gt;").Replace(text)
+}
+
+// GenerateMermaid returns the schema graph as a Mermaid flowchart. Flowcharts are used instead of
+// ER diagrams since they allow coloring nodes and edges.
+func GenerateMermaid(graph internal.SchemaGraph) string {
+	var sb strings.Builder
+
+	sb.WriteString("flowchart LR\n")
+	for _, status := range []string{internal.StatusAdded, internal.StatusRemoved, internal.StatusChanged, internal.StatusUnchanged} {
+		colors := diagramColors[status]
+		fmt.Fprintf(&sb, "\tclassDef %s fill:%s,stroke:%s,text-align:left\n", status, colors.Fill, colors.Line)
+	}
+
+	ids := map[string]string{}
+	for i, table := range graph.Tables {
+		ids[table.Name] = fmt.Sprintf("t%d", i)
+
+		lines := []string{"<b>" + mermaidText(statusMarker(table.Status)+table.Name) + "</b>"}
+		for _, column := range table.Columns {
+			lines = append(lines, mermaidText(fmt.Sprintf("%s%s %s", statusMarker(column.Status), column.Name, column.DataType)))
+		}
+
+		fmt.Fprintf(&sb, "\t%s[\"%s\"]:::%s\n", ids[table.Name], strings.Join(lines, "<br/>"), table.Status)
+	}
+
+	for i, edge := range graph.Edges {
+		arrow := "-->"
+		if edge.Status == internal.StatusRemoved {
+			arrow = "-.->"
+		}
+
+		fmt.Fprintf(&sb, "\t%s %s|\"%s\"| %s\n", ids[edge.From], arrow, mermaidText(edge.Label), ids[edge.To])
+		fmt.Fprintf(&sb, "\tlinkStyle %d stroke:%s\n", i, diagramColors[edge.Status].Line)
+	}
+
+	return sb.String()
+}
+
+// SaveAsDiagram writes the schema graph in the dot or mermaid format.
+func SaveAsDiagram(format string, graph internal.SchemaGraph, output string) error {
+	content := GenerateDOT(graph)
+	if format == "mermaid" {
+		content = GenerateMermaid(graph)
+	}
+
+	return os.WriteFile(output, []byte(content), 0o644)
+}
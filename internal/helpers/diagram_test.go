@@ -0,0 +1,77 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+func TestSaveAsDiagram(t *testing.T) {
+	graph := internal.SchemaGraph{
+		Tables: []internal.GraphTable{
+			{Name: "customers", Status: internal.StatusUnchanged, Columns: []internal.GraphColumn{
+				{Name: "id", DataType: "integer", Status: internal.StatusUnchanged},
+			}},
+			{Name: "orders", Status: internal.StatusChanged, Columns: []internal.GraphColumn{
+				{Name: "customer_id", DataType: "integer", Status: internal.StatusAdded},
+				{Name: "tags", DataType: "map<text>", Status: internal.StatusRemoved},
+			}},
+		},
+		Edges: []internal.GraphEdge{
+			{Name: "orders_customer_id_fkey", From: "orders", To: "customers", Label: "customer_id -> id", Status: internal.StatusAdded},
+			{Name: "orders_old_fkey", From: "orders", To: "customers", Label: `"old" -> id`, Status: internal.StatusRemoved},
+		},
+	}
+
+	tests := []struct {
+		format   string
+		contains []string
+	}{
+		{
+			format: "dot",
+			contains: []string{
+				"digraph schema {\n",
+				`<tr><td bgcolor="#ffffff" colspan="2"><b>customers</b></td></tr>`,
+				`<tr><td bgcolor="#fff2cc" colspan="2"><b>~ orders</b></td></tr>`,
+				`<tr><td bgcolor="#d9ead3" align="left">+ customer_id</td><td bgcolor="#d9ead3" align="left">integer</td></tr>`,
+				`<td bgcolor="#f4cccc" align="left">map&lt;text&gt;</td>`,
+				`"orders" -> "customers" [label="customer_id -> id", color="#2e7d32", fontcolor="#2e7d32", style=solid];`,
+				`"orders" -> "customers" [label="\"old\" -> id", color="#c62828", fontcolor="#c62828", style=dashed];`,
+			},
+		},
+		{
+			format: "mermaid",
+			contains: []string{
+				"flowchart LR\n",
+				"\tclassDef added fill:#d9ead3,stroke:#2e7d32,text-align:left\n",
+				"\tt0[\"<b>customers</b><br/>id integer\"]:::unchanged\n",
+				"\tt1[\"<b>~ orders</b><br/>+ customer_id integer<br/>- tags map#lt;text#gt;\"]:::changed\n",
+				"\tt1 -->|\"customer_id -#gt; id\"| t0\n\tlinkStyle 0 stroke:#2e7d32\n",
+				"\tt1 -.->|\"#quot;old#quot; -#gt; id\"| t0\n\tlinkStyle 1 stroke:#c62828\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "schema."+tt.format)
+			if err := SaveAsDiagram(tt.format, graph, output); err != nil {
+				t.Fatalf("SaveAsDiagram() error = %v", err)
+			}
+
+			data, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range tt.contains {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("SaveAsDiagram(%q) = %q, want it to contain %q", tt.format, data, want)
+				}
+			}
+		})
+	}
+}
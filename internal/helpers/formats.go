@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"fmt"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+// OutputFormats lists the formats the comparison result can be saved as, in the order shown in the help text.
+var OutputFormats = []string{"excel", "html", "markdown", "junit", "sarif", "csv", "text", "dot", "mermaid"}
+
+// FormatExtension returns the file extension used for the output format.
+func FormatExtension(format string) string {
+	switch format {
+	case "html":
+		return ".html"
+	case "markdown":
+		return ".md"
+	case "junit":
+		return ".xml"
+	case "sarif":
+		return ".sarif"
+	case "csv":
+		return ".csv"
+	case "text":
+		return ".txt"
+	case "dot":
+		return ".dot"
+	case "mermaid":
+		return ".mmd"
+	default:
+		return ".xlsx"
+	}
+}
+
+// IsDiagramFormat reports whether the format renders the schema graph, which has to be built with
+// internal.BuildSchemaGraph and saved with SaveAsDiagram.
+func IsDiagramFormat(format string) bool {
+	return format == "dot" || format == "mermaid"
+}
+
+// SaveResult saves the comparison result to the output path in the given format. maxRows limits
+// the rows of each detail sheet of the Excel report, 0 keeps all of them.
+func SaveResult(format string, result internal.ComparisonResult, DB1Name string, DB2Name string, output string, maxRows int) error {
+	switch format {
+	case "excel":
+		return SaveAsExcel(result, DB1Name, DB2Name, output, maxRows)
+	case "html":
+		return SaveAsHTML(result, DB1Name, DB2Name, output)
+	case "markdown":
+		return SaveAsMarkdown(result, DB1Name, DB2Name, output)
+	case "junit":
+		return SaveAsJUnit(result, DB1Name, DB2Name, output)
+	case "sarif":
+		return SaveAsSARIF(result, DB1Name, DB2Name, output)
+	case "csv":
+		return SaveAsCSV(result, DB1Name, DB2Name, output)
+	case "text":
+		return SaveAsText(result, DB1Name, DB2Name, output)
+	}
+
+	return fmt.Errorf("unsupported output format %s", format)
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/CDavidSV/go-dbcompare/internal"
 	_ "github.com/lib/pq"
@@ -18,12 +19,19 @@ var (
 	saveCursorPosition = "\033[s"
 )
 
+// ClearLine restores the cursor position and clears the line of the status messages, which are written
+// to stderr so they do not mix with a result printed to stdout. Nothing is written when stderr is not a terminal.
 func ClearLine() {
-	fmt.Print(clearLine)
+	if IsTerminal(os.Stderr) {
+		fmt.Fprint(os.Stderr, clearLine)
+	}
 }
 
+// SaveCursorPosition saves the cursor position of the status messages, see ClearLine.
 func SaveCursorPosition() {
-	fmt.Print(saveCursorPosition)
+	if IsTerminal(os.Stderr) {
+		fmt.Fprint(os.Stderr, saveCursorPosition)
+	}
 }
 
 func GetDataSourceName(driverName string, username, password, host, database string, port uint16, connParams map[string]string) string {
@@ -84,143 +92,501 @@ func LoadConfigurationFile(path string) (internal.Configuration, error) {
 		return conf, err
 	}
 
+	err = conf.DataFilters.Validate()
+	if err != nil {
+		return conf, err
+	}
+
+	err = conf.Comparators.Validate()
+	if err != nil {
+		return conf, err
+	}
+
 	return conf, nil
 }
 
-func SaveAsExcel(result internal.ComparisonResult, DB1Name string, DB2Name string, output string) error {
-	f := excelize.NewFile()
-	defer f.Close()
-	sheetName := "Table Comparison"
+// excelStyles are the cell styles shared by the sheets of the workbook.
+type excelStyles struct {
+	header  int
+	border  int
+	flagged int
+	link    int
+	// changed is a conditional style applied to the values that differ between both databases
+	changed int
+}
 
-	f.SetSheetName(f.GetSheetName(0), sheetName)
+func newExcelStyles(f *excelize.File) excelStyles {
+	border := []excelize.Border{
+		{Type: "left", Color: "#000000", Style: 1},
+		{Type: "top", Color: "#000000", Style: 1},
+		{Type: "right", Color: "#000000", Style: 1},
+		{Type: "bottom", Color: "#000000", Style: 1},
+	}
 
-	style1, _ := f.NewStyle(&excelize.Style{
-		Alignment: &excelize.Alignment{
-			Horizontal: "center",
-			Vertical:   "center",
-		},
-		Border: []excelize.Border{
-			{Type: "left", Color: "#000000", Style: 1},
-			{Type: "top", Color: "#000000", Style: 1},
-			{Type: "right", Color: "#000000", Style: 1},
-			{Type: "bottom", Color: "#000000", Style: 1},
-		},
-		Font: &excelize.Font{
-			Bold: true,
-		},
+	var styles excelStyles
+	styles.header, _ = f.NewStyle(&excelize.Style{
+		Border: border,
+		Font:   &excelize.Font{Bold: true},
+		Fill:   excelize.Fill{Type: "pattern", Color: []string{"#d9d9d9"}, Pattern: 1},
 	})
-
-	errorStyle, _ := f.NewStyle(&excelize.Style{
-		Alignment: &excelize.Alignment{
-			Vertical: "center",
-		},
-		Fill: excelize.Fill{
-			Type:    "pattern",
-			Color:   []string{"#f4cccc"},
-			Pattern: 1,
-		},
-		Border: []excelize.Border{
-			{Type: "left", Color: "#000000", Style: 1},
-			{Type: "top", Color: "#000000", Style: 1},
-			{Type: "right", Color: "#000000", Style: 1},
-			{Type: "bottom", Color: "#000000", Style: 1},
-		},
-		Font: &excelize.Font{
-			Bold: true,
-		},
+	styles.border, _ = f.NewStyle(&excelize.Style{Border: border})
+	styles.flagged, _ = f.NewStyle(&excelize.Style{
+		Border: border,
+		Font:   &excelize.Font{Bold: true},
+		Fill:   excelize.Fill{Type: "pattern", Color: []string{"#f4cccc"}, Pattern: 1},
 	})
-
-	borderStyle, _ := f.NewStyle(&excelize.Style{
-		Border: []excelize.Border{
-			{Type: "left", Color: "#000000", Style: 1},
-			{Type: "top", Color: "#000000", Style: 1},
-			{Type: "right", Color: "#000000", Style: 1},
-			{Type: "bottom", Color: "#000000", Style: 1},
-		},
+	styles.link, _ = f.NewStyle(&excelize.Style{
+		Border: border,
+		Font:   &excelize.Font{Color: "#1155cc", Underline: "single"},
+	})
+	styles.changed, _ = f.NewConditionalStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#f4cccc"}, Pattern: 1},
 	})
 
-	// Set titles
-	f.SetCellValue(sheetName, "A1", "Num")
-	f.SetCellValue(sheetName, "B1", fmt.Sprintf("Database 1 (%s)", DB1Name))
-	f.SetCellValue(sheetName, "C1", fmt.Sprintf("Database 2 (%s)", DB2Name))
-	f.SetCellStyle(sheetName, "A1", "C1", borderStyle)
+	return styles
+}
+
+// excelRowLimit is the maximum number of rows of a worksheet. Detail sheets with more rows continue
+// in a new sheet.
+var excelRowLimit = 1048576
+
+// summaryEntry is a row of the summary sheet, linking to the first detail row of the table.
+type summaryEntry struct {
+	Table string
+	Kind  string
+	Sheet string
+	// Index is the position of the first detail row, -1 links to the top of the sheet
+	Index int
+	Count int
+}
+
+func cellName(col, row int) string {
+	name, _ := excelize.CoordinatesToCellName(col, row)
+	return name
+}
+
+// rolloverSheetName returns the name of the nth sheet of a detail sheet, starting at 0.
+func rolloverSheetName(name string, n int) string {
+	if n == 0 {
+		return name
+	}
+
+	suffix := fmt.Sprintf(" (%d)", n+1)
+	if len(name)+len(suffix) > 31 {
+		name = name[:31-len(suffix)]
+	}
+
+	return name + suffix
+}
 
-	// Fill in the table data
-	firstCell := 2
-	for i, DB1Val := range result.DifferencesResult.DB1 {
+// detailLocation returns the sheet and row of the detail row at the given index.
+func detailLocation(name string, index int) (string, int) {
+	if index < 0 {
+		return name, 1
+	}
 
-		cellNameStart, _ := excelize.CoordinatesToCellName(1, firstCell)
-		cellNameEnd, _ := excelize.CoordinatesToCellName(1, firstCell+6)
-		f.SetCellStyle(sheetName, cellNameStart, cellNameEnd, style1)
-		f.MergeCell(sheetName, cellNameStart, cellNameEnd)
+	rowsPerSheet := excelRowLimit - 1
+	return rolloverSheetName(name, index/rowsPerSheet), index%rowsPerSheet + 2
+}
 
-		f.SetCellValue(sheetName, cellNameStart, i+1)
+func sheetLink(name string, index int) string {
+	sheet, row := detailLocation(name, index)
+	return fmt.Sprintf("'%s'!A%d", sheet, row)
+}
 
-		DB2Val := result.DifferencesResult.DB2[i]
+// newStreamWriter returns a stream writer for the sheet with the column widths and panes set, so rows
+// are written to the file instead of being kept in the workbook in memory. Sheet level settings such
+// as filters or conditional formats have to be set before calling it.
+func newStreamWriter(f *excelize.File, sheet string, widths []float64, panes *excelize.Panes) (*excelize.StreamWriter, error) {
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
 
-		DB1ValSlice := []string{
-			fmt.Sprintf("Table Name: %s", DB1Val.TableName),
-			fmt.Sprintf("Column Name: %s", DB1Val.ColumnName),
-			fmt.Sprintf("Data Type: %s", DB1Val.DataType),
-			fmt.Sprintf("Column Default: %s", DB1Val.ColumnDefault),
-			fmt.Sprintf("Is Nullable: %s", DB1Val.IsNullable),
-			fmt.Sprintf("Char Max Len: %d", DB1Val.CharMaxLen),
-			fmt.Sprintf("Numeric Precision: %d", DB1Val.NumericPrecision),
+	// widths holds the width of each column starting at column A, 0 keeps the default width
+	for i, width := range widths {
+		if width == 0 {
+			continue
 		}
-		DB2ValSlice := []string{
-			fmt.Sprintf("Table Name: %s", DB2Val.TableName),
-			fmt.Sprintf("Column Name: %s", DB2Val.ColumnName),
-			fmt.Sprintf("Data Type: %s", DB2Val.DataType),
-			fmt.Sprintf("Column Default: %s", DB2Val.ColumnDefault),
-			fmt.Sprintf("Is Nullable: %s", DB2Val.IsNullable),
-			fmt.Sprintf("Char Max Len: %d", DB2Val.CharMaxLen),
-			fmt.Sprintf("Numeric Precision: %d", DB2Val.NumericPrecision),
+
+		if err = sw.SetColWidth(i+1, i+1, width); err != nil {
+			return nil, err
 		}
+	}
 
-		// Compare and add to cell
-		for j, v1 := range DB1ValSlice {
-			v2 := DB2ValSlice[j]
+	if panes != nil {
+		if err = sw.SetPanes(panes); err != nil {
+			return nil, err
+		}
+	}
+
+	return sw, nil
+}
+
+// streamSheet writes the rows of a small sheet with a stream writer.
+func streamSheet(f *excelize.File, sheet string, rows [][]any, widths []float64, panes *excelize.Panes) error {
+	sw, err := newStreamWriter(f, sheet, widths, panes)
+	if err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		if err = sw.SetRow(cellName(1, i+1), row); err != nil {
+			return err
+		}
+	}
+
+	return sw.Flush()
+}
+
+func styledRow(values []string, style int) []any {
+	row := make([]any, len(values))
+	for i, v := range values {
+		row[i] = excelize.Cell{StyleID: style, Value: v}
+	}
+
+	return row
+}
+
+// nextRow returns the values and style of the next row of a detail sheet.
+type nextRow func() ([]string, int)
+
+// sliceRows returns the rows one at a time.
+func sliceRows(rows [][]string, rowStyles []int) nextRow {
+	i := -1
+	return func() ([]string, int) {
+		i++
+		return rows[i], rowStyles[i]
+	}
+}
+
+// writeDetailSheets writes the headers and count rows taken from next, one at a time, rolling over to a
+// new sheet every time the row limit is reached. At most maxRows rows are written, 0 writes all of them,
+// followed by a note with the number of rows left out. Every sheet has its header row and first
+// frozenColumns columns frozen, an autofilter, and conditional formatting highlighting the pairs of
+// columns whose values differ.
+func writeDetailSheets(f *excelize.File, name string, headers []string, count int, next nextRow, maxRows int, frozenColumns int, pairs [][2]int, styles excelStyles) error {
+	written, total := count, count
+	if maxRows > 0 && count > maxRows {
+		written, total = maxRows, maxRows+1
+	}
 
-			cellNameDB1, _ := excelize.CoordinatesToCellName(2, firstCell+j)
-			cellNameDB2, _ := excelize.CoordinatesToCellName(3, firstCell+j)
+	rowsPerSheet := excelRowLimit - 1
+	widths := make([]float64, len(headers))
+	for i := range widths {
+		widths[i] = 25
+	}
+
+	for n := 0; n == 0 || n*rowsPerSheet < total; n++ {
+		sheet := rolloverSheetName(name, n)
+		if _, err := f.NewSheet(sheet); err != nil {
+			return err
+		}
+
+		sheetRows := min(total-n*rowsPerSheet, rowsPerSheet)
+		lastRow := sheetRows + 1
+
+		if err := f.AutoFilter(sheet, fmt.Sprintf("A1:%s", cellName(len(headers), lastRow)), nil); err != nil {
+			return err
+		}
+
+		if lastRow > 1 {
+			for _, pair := range pairs {
+				DB1Col, _ := excelize.ColumnNumberToName(pair[0])
+				DB2Col, _ := excelize.ColumnNumberToName(pair[1])
+
+				err := f.SetConditionalFormat(sheet, fmt.Sprintf("%s2:%s%d", DB1Col, DB2Col, lastRow), []excelize.ConditionalFormatOptions{
+					{Type: "formula", Criteria: fmt.Sprintf("$%s2<>$%s2", DB1Col, DB2Col), Format: styles.changed},
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		panes := &excelize.Panes{
+			Freeze:      true,
+			XSplit:      frozenColumns,
+			YSplit:      1,
+			TopLeftCell: cellName(frozenColumns+1, 2),
+			ActivePane:  "bottomRight",
+		}
+		if frozenColumns == 0 {
+			panes.ActivePane = "bottomLeft"
+		}
+
+		sw, err := newStreamWriter(f, sheet, widths, panes)
+		if err != nil {
+			return err
+		}
 
-			f.SetCellValue(sheetName, cellNameDB1, v1)
-			f.SetCellValue(sheetName, cellNameDB2, v2)
+		if err = sw.SetRow("A1", styledRow(headers, styles.header)); err != nil {
+			return err
+		}
 
-			if v1 != v2 {
-				f.SetCellStyle(sheetName, cellNameDB1, cellNameDB2, errorStyle)
+		for i := 0; i < sheetRows; i++ {
+			var row []any
+			if n*rowsPerSheet+i < written {
+				values, style := next()
+				row = styledRow(values, style)
 			} else {
-				f.SetCellStyle(sheetName, cellNameDB1, cellNameDB2, borderStyle)
+				row = styledRow([]string{fmt.Sprintf("%d more rows not shown, the limit is %d rows", count-maxRows, maxRows)}, styles.flagged)
+			}
+
+			if err = sw.SetRow(cellName(1, i+2), row); err != nil {
+				return err
+			}
+		}
+
+		if err = sw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func metadataRows(result internal.ComparisonResult, DB1Name, DB2Name string, sections []reportSection) [][]string {
+	metadata := result.Metadata
+	options := metadata.Options
+
+	rows := [][]string{
+		{"Started At", metadata.StartedAt.Format("2006-01-02 15:04:05 MST")},
+		{"Duration", metadata.Duration.Round(time.Millisecond).String()},
+		{fmt.Sprintf("Host (%s)", DB1Name), metadata.DB1Host},
+		{fmt.Sprintf("Host (%s)", DB2Name), metadata.DB2Host},
+		{"Privileges", fmt.Sprint(options.Privileges)},
+		{"Strict Partition Bounds", fmt.Sprint(options.StrictPartitionBounds)},
+		{"Strict Column Order", fmt.Sprint(options.StrictColumnOrder)},
+		{"Comments", fmt.Sprint(options.Comments)},
+		{"Settings", fmt.Sprint(options.Settings)},
+		{"Ignored Settings", strings.Join(options.IgnoredSettings, ", ")},
+		{"Statistics", fmt.Sprint(options.Stats)},
+		{"Exact Counts", fmt.Sprint(options.ExactCounts)},
+		{"Statistics Threshold %", fmt.Sprint(options.StatsThreshold)},
+		{"Column Profiles", fmt.Sprint(options.Profile)},
+		{"Profile Tolerance %", fmt.Sprint(options.ProfileOptions.Tolerance)},
+		{"Profile Top Values", fmt.Sprint(options.ProfileOptions.TopN)},
+		{"Data", fmt.Sprint(options.Data)},
+		{"Data Max Differences", fmt.Sprint(options.DataOptions.MaxDifferences)},
+		{"Data Global Filter", options.DataOptions.Filters.Global},
+	}
+
+	for _, section := range sections {
+		if section.Note != "" {
+			rows = append(rows, []string{section.Name, section.Note})
+		}
+	}
+
+	return rows
+}
+
+// SaveAsExcel writes the comparison result as a workbook with a summary sheet linking to the details,
+// a sheet with one row per column difference, one sheet per kind of difference and the metadata of the run.
+// Sheets are written with stream writers. Detail sheets keep at most maxRows rows, 0 keeps all of them,
+// and continue in a new sheet when the row limit of a worksheet is reached.
+func SaveAsExcel(result internal.ComparisonResult, DB1Name string, DB2Name string, output string, maxRows int) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles := newExcelStyles(f)
+	tables := tableDifferences(result)
+	sections := buildReportSections(result, DB1Name, DB2Name, true)
+
+	statusKinds := map[internal.ChangeType]string{
+		internal.ChangeAdded:    fmt.Sprintf("Columns only in %s", DB1Name),
+		internal.ChangeRemoved:  fmt.Sprintf("Columns only in %s", DB2Name),
+		internal.ChangeModified: "Modified columns",
+	}
+
+	// Column differences, one row per column
+	entries := []summaryEntry{}
+	headers := []string{"Table Name", "Column Name", "Change"}
+	attributes := internal.ColumnData{}.ComparedAttributes(result.Metadata.Options.StrictColumnOrder)
+	pairs := [][2]int{}
+	for _, attr := range attributes {
+		headers = append(headers, fmt.Sprintf("%s (%s)", attr.Name, DB1Name), fmt.Sprintf("%s (%s)", attr.Name, DB2Name))
+		pairs = append(pairs, [2]int{len(headers) - 1, len(headers)})
+	}
+
+	rows := [][]string{}
+	rowStyles := []int{}
+	for _, table := range tables {
+		tableEntries := map[internal.ChangeType]int{}
+
+		for _, column := range table.Columns {
+			row := []string{table.TableName, column.Column, string(column.Type)}
+			for _, attr := range column.Attributes {
+				row = append(row, attr.DB1(), attr.DB2())
+			}
+			rows = append(rows, row)
+			rowStyles = append(rowStyles, styles.border)
+
+			index, ok := tableEntries[column.Type]
+			if !ok {
+				// Rows past the limit link to the truncation note
+				first := len(rows) - 1
+				if maxRows > 0 {
+					first = min(first, maxRows)
+				}
+
+				entries = append(entries, summaryEntry{Table: table.TableName, Kind: statusKinds[column.Type], Sheet: "Differences", Index: first})
+				index = len(entries) - 1
+				tableEntries[column.Type] = index
 			}
+			entries[index].Count++
+		}
+	}
 
+	totals := []summaryEntry{}
+	for _, status := range []internal.ChangeType{internal.ChangeAdded, internal.ChangeRemoved, internal.ChangeModified} {
+		total := summaryEntry{Kind: statusKinds[status], Sheet: "Differences", Index: -1}
+		for _, entry := range entries {
+			if entry.Kind == statusKinds[status] {
+				total.Count += entry.Count
+			}
 		}
+		totals = append(totals, total)
+	}
+
+	f.SetSheetName(f.GetSheetName(0), "Summary")
 
-		firstCell += 7
+	if err := writeDetailSheets(f, "Differences", headers, len(rows), sliceRows(rows, rowStyles), maxRows, 3, pairs, styles); err != nil {
+		return err
 	}
-	f.SetColWidth(sheetName, "B", "C", 70)
 
-	// Create a new sheet to show missing tables in each database
-	sheetName = "Missing tables"
-	f.NewSheet(sheetName)
+	// One sheet per kind of difference
+	for _, section := range sections {
+		if section.Streamed {
+			entries, totals = appendRowDifferenceEntries(result, section.Name, maxRows, entries, totals)
+			if err := writeRowDifferences(f, result, section, maxRows, styles); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tableColumn := -1
+		for i, header := range section.Headers {
+			if header == "Table Name" {
+				tableColumn = i
+				break
+			}
+		}
+
+		rowStyles = make([]int, len(section.Rows))
+		tableEntries := map[string]int{}
+		total := summaryEntry{Kind: section.Name, Sheet: section.Name, Index: -1}
+
+		for i, row := range section.Rows {
+			rowStyles[i] = styles.border
+			if section.Flagged[i] && !section.Paired {
+				rowStyles[i] = styles.flagged
+			}
+
+			if !section.Flagged[i] {
+				continue
+			}
+			total.Count++
 
-	f.SetCellValue(sheetName, "A1", fmt.Sprintf("Tables missing in %s (Present in %s)", DB1Name, DB2Name))
-	f.SetCellValue(sheetName, "B1", fmt.Sprintf("Tables missing in %s (Present in %s)", DB2Name, DB1Name))
-	f.SetCellStyle(sheetName, "A1", "B1", borderStyle)
+			table := ""
+			if tableColumn != -1 {
+				table = row[tableColumn]
+			}
 
-	// Add missing tables
-	for i, v := range result.MissingTablesInDB1 {
-		cellNameDB1, _ := excelize.CoordinatesToCellName(1, i+2)
-		f.SetCellValue(sheetName, cellNameDB1, v)
-		f.SetCellStyle(sheetName, cellNameDB1, cellNameDB1, borderStyle)
+			index, ok := tableEntries[table]
+			if !ok {
+				// Rows past the limit link to the truncation note
+				first := i
+				if maxRows > 0 {
+					first = min(i, maxRows)
+				}
+
+				entries = append(entries, summaryEntry{Table: table, Kind: section.Name, Sheet: section.Name, Index: first})
+				index = len(entries) - 1
+				tableEntries[table] = index
+			}
+			entries[index].Count++
+		}
+		totals = append(totals, total)
+
+		sectionPairs := [][2]int{}
+		if section.Paired {
+			sectionPairs = append(sectionPairs, [2]int{len(section.Headers) - 1, len(section.Headers)})
+		}
+
+		if err := writeDetailSheets(f, section.Name, section.Headers, len(section.Rows), sliceRows(section.Rows, rowStyles), maxRows, 0, sectionPairs, styles); err != nil {
+			return err
+		}
 	}
 
-	for i, v := range result.MissingTablesInDB2 {
-		cellNameDB2, _ := excelize.CoordinatesToCellName(2, i+2)
-		f.SetCellValue(sheetName, cellNameDB2, v)
-		f.SetCellStyle(sheetName, cellNameDB2, cellNameDB2, borderStyle)
+	// Summary with the totals per kind and the counts per table, linked to the detail rows
+	sheetName := "Summary"
+	for i, total := range totals {
+		f.SetCellHyperLink(sheetName, cellName(1, i+2), sheetLink(total.Sheet, total.Index), "Location")
+	}
+	for i, entry := range entries {
+		f.SetCellHyperLink(sheetName, cellName(5, i+2), sheetLink(entry.Sheet, entry.Index), "Location")
+	}
+	if len(entries) > 0 {
+		f.AutoFilter(sheetName, fmt.Sprintf("D1:F%d", len(entries)+1), nil)
+	}
+
+	summaryRows := [][]any{{
+		excelize.Cell{StyleID: styles.header, Value: "Kind"},
+		excelize.Cell{StyleID: styles.header, Value: "Total"},
+		nil,
+		excelize.Cell{StyleID: styles.header, Value: "Table Name"},
+		excelize.Cell{StyleID: styles.header, Value: "Kind"},
+		excelize.Cell{StyleID: styles.header, Value: "Count"},
+	}}
+	for i := 0; i < max(len(totals), len(entries)); i++ {
+		row := []any{nil, nil, nil}
+		if i < len(totals) {
+			row = []any{
+				excelize.Cell{StyleID: styles.link, Value: totals[i].Kind},
+				excelize.Cell{StyleID: styles.border, Value: totals[i].Count},
+				nil,
+			}
+		}
+
+		if i < len(entries) {
+			table := entries[i].Table
+			if table == "" {
+				table = "(database)"
+			}
+
+			row = append(row,
+				excelize.Cell{StyleID: styles.border, Value: table},
+				excelize.Cell{StyleID: styles.link, Value: entries[i].Kind},
+				excelize.Cell{StyleID: styles.border, Value: entries[i].Count},
+			)
+		}
+
+		summaryRows = append(summaryRows, row)
+	}
+
+	err := streamSheet(f, sheetName, summaryRows, []float64{30, 0, 0, 30, 30}, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+	if err != nil {
+		return err
+	}
+
+	// Metadata of the run
+	sheetName = "Metadata"
+	if _, err = f.NewSheet(sheetName); err != nil {
+		return err
+	}
+
+	metadata := [][]any{styledRow([]string{"Property", "Value"}, styles.header)}
+	for _, row := range metadataRows(result, DB1Name, DB2Name, sections) {
+		metadata = append(metadata, styledRow(row, styles.border))
+	}
+
+	if err = streamSheet(f, sheetName, metadata, []float64{30, 50}, nil); err != nil {
+		return err
 	}
 
-	f.SetColWidth(sheetName, "A", "B", 70)
+	f.SetActiveSheet(0)
 
 	file, err := os.Create(output)
 	if err != nil {
@@ -235,3 +601,57 @@ func SaveAsExcel(result internal.ComparisonResult, DB1Name string, DB2Name strin
 
 	return nil
 }
+
+// appendRowDifferenceEntries adds the summary entries of the row differences, one per table.
+func appendRowDifferenceEntries(result internal.ComparisonResult, sheet string, maxRows int, entries, totals []summaryEntry) ([]summaryEntry, []summaryEntry) {
+	total := summaryEntry{Kind: sheet, Sheet: sheet, Index: -1}
+
+	for _, table := range result.Data.Tables {
+		if len(table.Differences) == 0 {
+			continue
+		}
+
+		// Rows past the limit link to the truncation note
+		first := total.Count
+		if maxRows > 0 {
+			first = min(first, maxRows)
+		}
+
+		entries = append(entries, summaryEntry{Table: table.TableName, Kind: sheet, Sheet: sheet, Index: first, Count: len(table.Differences)})
+		total.Count += len(table.Differences)
+	}
+
+	return entries, append(totals, total)
+}
+
+// writeRowDifferences streams the row differences of the data comparison to their sheets, rendering
+// each row only when it is written.
+func writeRowDifferences(f *excelize.File, result internal.ComparisonResult, section reportSection, maxRows int, styles excelStyles) error {
+	count := 0
+	for _, table := range result.Data.Tables {
+		count += len(table.Differences)
+	}
+
+	tableIndex, diffIndex := 0, 0
+	next := func() ([]string, int) {
+		for diffIndex >= len(result.Data.Tables[tableIndex].Differences) {
+			tableIndex, diffIndex = tableIndex+1, 0
+		}
+
+		table := result.Data.Tables[tableIndex]
+		diffIndex++
+
+		return rowDifference(table, table.Differences[diffIndex-1]), styles.border
+	}
+
+	pairs := [][2]int{{len(section.Headers) - 1, len(section.Headers)}}
+	return writeDetailSheets(f, section.Name, section.Headers, count, next, maxRows, 0, pairs, styles)
+}
+
+func exactRows(stats internal.TableStats) string {
+	if stats.ExactRows < 0 {
+		return ""
+	}
+
+	return fmt.Sprint(stats.ExactRows)
+}
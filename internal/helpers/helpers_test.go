@@ -0,0 +1,211 @@
+package helpers
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSaveAsExcel(t *testing.T) {
+	withSettings := testResult()
+	withSettings.Settings = &internal.SettingsComparison{Differences: []internal.SettingDifference{
+		{Name: "work_mem", Category: "Resource Usage", DB1: "4MB", DB2: "64MB", Change: internal.ChangeModified},
+	}}
+
+	tests := []struct {
+		name    string
+		result  internal.ComparisonResult
+		sheets  []string
+		summary [][]string
+		// links maps the cells of the summary sheet to the location they link to
+		links map[string]string
+	}{
+		{
+			name:   "columns and tables",
+			result: testResult(),
+			sheets: []string{"Summary", "Differences", "Missing tables", "Row Level Security", "Partition Differences", "Partitions", "Metadata"},
+			summary: [][]string{
+				{"Kind", "Total", "", "Table Name", "Kind", "Count"},
+				{"Columns only in DB1", "1", "", "orders", "Modified columns", "1"},
+				{"Columns only in DB2", "1", "", "orders", "Columns only in DB1", "1"},
+				{"Modified columns", "1", "", "orders", "Columns only in DB2", "1"},
+				{"Missing tables", "2", "", "customers", "Missing tables", "1"},
+				{"Row Level Security", "0", "", "invoices", "Missing tables", "1"},
+				{"Partition Differences", "0"},
+				{"Partitions", "0"},
+			},
+			links: map[string]string{
+				"A2": "'Differences'!A1",
+				"A5": "'Missing tables'!A1",
+				"E2": "'Differences'!A2",
+				"E4": "'Differences'!A4",
+				"E6": "'Missing tables'!A3",
+			},
+		},
+		{
+			name:   "settings",
+			result: withSettings,
+			sheets: []string{"Summary", "Differences", "Missing tables", "Row Level Security", "Partition Differences", "Partitions", "Settings", "Metadata"},
+			summary: [][]string{
+				{"Kind", "Total", "", "Table Name", "Kind", "Count"},
+				{"Columns only in DB1", "1", "", "orders", "Modified columns", "1"},
+				{"Columns only in DB2", "1", "", "orders", "Columns only in DB1", "1"},
+				{"Modified columns", "1", "", "orders", "Columns only in DB2", "1"},
+				{"Missing tables", "2", "", "customers", "Missing tables", "1"},
+				{"Row Level Security", "0", "", "invoices", "Missing tables", "1"},
+				{"Partition Differences", "0", "", "(database)", "Settings", "1"},
+				{"Partitions", "0"},
+				{"Settings", "1"},
+			},
+			links: map[string]string{
+				"A9": "'Settings'!A1",
+				"E7": "'Settings'!A2",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "result.xlsx")
+			if err := SaveAsExcel(tt.result, "DB1", "DB2", output, 0); err != nil {
+				t.Fatalf("SaveAsExcel() error = %v", err)
+			}
+
+			f, err := excelize.OpenFile(output)
+			if err != nil {
+				t.Fatalf("SaveAsExcel() wrote an invalid workbook: %v", err)
+			}
+			defer f.Close()
+
+			if got := f.GetSheetList(); !reflect.DeepEqual(got, tt.sheets) {
+				t.Errorf("sheets = %q, want %q", got, tt.sheets)
+			}
+
+			summary, err := f.GetRows("Summary")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(summary, tt.summary) {
+				t.Errorf("summary = %q, want %q", summary, tt.summary)
+			}
+
+			for cell, want := range tt.links {
+				if _, got, _ := f.GetCellHyperLink("Summary", cell); got != want {
+					t.Errorf("link of %s = %q, want %q", cell, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRolloverSheetName(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		want string
+	}{
+		{name: "Differences", n: 0, want: "Differences"},
+		{name: "Differences", n: 1, want: "Differences (2)"},
+		{name: "Column Profile Differences Long", n: 11, want: "Column Profile Differences (12)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := rolloverSheetName(tt.name, tt.n); got != tt.want {
+				t.Errorf("rolloverSheetName(%q, %d) = %q, want %q", tt.name, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveAsExcelRollover(t *testing.T) {
+	limit := excelRowLimit
+	excelRowLimit = 4
+	t.Cleanup(func() { excelRowLimit = limit })
+
+	DB1Tables := map[string]map[string]internal.ColumnData{"orders": {}}
+	DB2Tables := map[string]map[string]internal.ColumnData{"orders": {}}
+	for i := 1; i <= 7; i++ {
+		col := testColumn("orders", fmt.Sprintf("column_%d", i), i)
+		DB2Tables["orders"][col.ColumnName] = col
+		col.DataType = "bigint"
+		DB1Tables["orders"][col.ColumnName] = col
+	}
+	result, _ := internal.CompareTables(DB1Tables, DB2Tables, internal.CompareOptions{})
+
+	tests := []struct {
+		name    string
+		maxRows int
+		// sheets maps the detail sheets to the second column of their rows, headers included
+		sheets map[string][]string
+	}{
+		{
+			name:    "all rows",
+			maxRows: 0,
+			sheets: map[string][]string{
+				"Differences":     {"Column Name", "column_1", "column_2", "column_3"},
+				"Differences (2)": {"Column Name", "column_4", "column_5", "column_6"},
+				"Differences (3)": {"Column Name", "column_7"},
+			},
+		},
+		{
+			name:    "truncated",
+			maxRows: 5,
+			sheets: map[string][]string{
+				"Differences":     {"Column Name", "column_1", "column_2", "column_3"},
+				"Differences (2)": {"Column Name", "column_4", "column_5", ""},
+				"Differences (3)": nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "result.xlsx")
+			if err := SaveAsExcel(result, "DB1", "DB2", output, tt.maxRows); err != nil {
+				t.Fatalf("SaveAsExcel() error = %v", err)
+			}
+
+			f, err := excelize.OpenFile(output)
+			if err != nil {
+				t.Fatalf("SaveAsExcel() wrote an invalid workbook: %v", err)
+			}
+			defer f.Close()
+
+			for sheet, want := range tt.sheets {
+				rows, err := f.GetRows(sheet)
+				if want == nil {
+					if err == nil {
+						t.Errorf("sheet %s exists, want it to be left out", sheet)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				got := []string{}
+				for _, row := range rows {
+					if len(row) < 2 {
+						row = append(row, "")
+					}
+					got = append(got, row[1])
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("sheet %s = %q, want %q", sheet, got, want)
+				}
+			}
+
+			if tt.maxRows > 0 {
+				note, _ := f.GetCellValue("Differences (2)", "A4")
+				if want := fmt.Sprintf("%d more rows not shown, the limit is %d rows", 7-tt.maxRows, tt.maxRows); note != want {
+					t.Errorf("truncation note = %q, want %q", note, want)
+				}
+			}
+		})
+	}
+}
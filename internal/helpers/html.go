@@ -0,0 +1,62 @@
+package helpers
+
+import (
+	_ "embed"
+	"html/template"
+	"os"
+	"time"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+//go:embed templates/report.html
+var reportTemplate string
+
+type htmlReport struct {
+	DB1Name     string
+	DB2Name     string
+	GeneratedAt string
+	Tables      []tableDifference
+	Added       int
+	Removed     int
+	Modified    int
+	Sections    []reportSection
+}
+
+// SaveAsHTML writes the comparison result as a single HTML file. Styles and scripts are embedded
+// so the report can be opened offline.
+func SaveAsHTML(result internal.ComparisonResult, DB1Name string, DB2Name string, output string) error {
+	tmpl, err := template.New("report").Parse(reportTemplate)
+	if err != nil {
+		return err
+	}
+
+	report := htmlReport{
+		DB1Name:     DB1Name,
+		DB2Name:     DB2Name,
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Tables:      tableDifferences(result),
+		Sections:    reportSections(result, DB1Name, DB2Name),
+	}
+
+	for _, table := range report.Tables {
+		for _, column := range table.Columns {
+			switch column.Type {
+			case internal.ChangeAdded:
+				report.Added++
+			case internal.ChangeRemoved:
+				report.Removed++
+			default:
+				report.Modified++
+			}
+		}
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return tmpl.Execute(file, report)
+}
@@ -0,0 +1,44 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveAsHTML(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "result.html")
+	if err := SaveAsHTML(testResult(), "<b>prod</b>", "staging", output); err != nil {
+		t.Fatalf("SaveAsHTML() error = %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := string(data)
+
+	tests := []struct {
+		name     string
+		text     string
+		contains bool
+	}{
+		{name: "escaped database name", text: "Database comparison: &lt;b&gt;prod&lt;/b&gt; vs staging", contains: true},
+		{name: "unescaped database name", text: "<b>prod</b>", contains: false},
+		{name: "table with column differences", text: `<details class="table" data-search="orders">`, contains: true},
+		{name: "added column", text: `<details class="column" data-status="added" data-search="note">`, contains: true},
+		{name: "removed column", text: `<details class="column" data-status="removed" data-search="status">`, contains: true},
+		{name: "missing tables section", text: "<h2>Missing tables</h2>", contains: true},
+		{name: "external script", text: "<script src=", contains: false},
+		{name: "external stylesheet", text: "<link", contains: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Contains(report, tt.text); got != tt.contains {
+				t.Errorf("report contains %q = %v, want %v", tt.text, got, tt.contains)
+			}
+		})
+	}
+}
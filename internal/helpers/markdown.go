@@ -0,0 +1,167 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+// markdownCommentLimit is the maximum number of characters of a GitHub comment.
+const markdownCommentLimit = 65536
+
+// markdownReserve leaves room for closing tags and truncation notes once the limit is reached.
+const markdownReserve = 1024
+
+var markdownEscaper = strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>", "<", "&lt;", ">", "&gt;")
+
+func markdownCell(value string) string {
+	if value == "" {
+		return " "
+	}
+
+	return markdownEscaper.Replace(value)
+}
+
+func markdownRow(values ...string) string {
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = markdownCell(v)
+	}
+
+	return "| " + strings.Join(cells, " | ") + " |\n"
+}
+
+// markdownBlock is a collapsible section of the report. Rows are dropped once the size limit is reached.
+type markdownBlock struct {
+	Summary string
+	Headers []string
+	Rows    [][]string
+}
+
+// markdownColumnRows returns one row per changed attribute of the column.
+func markdownColumnRows(column internal.Change) [][]string {
+	rows := [][]string{}
+
+	switch column.Type {
+	case internal.ChangeAdded, internal.ChangeRemoved:
+		if attr, ok := column.Attribute("Data Type"); ok {
+			rows = append(rows, []string{column.Column, string(column.Type), attr.Name, attr.DB1(), attr.DB2()})
+		}
+	default:
+		for _, attr := range column.ChangedAttributes() {
+			rows = append(rows, []string{column.Column, string(column.Type), attr.Name, attr.DB1(), attr.DB2()})
+		}
+	}
+
+	return rows
+}
+
+// GenerateMarkdown returns the comparison result as markdown meant to be posted as a pull request
+// comment. Only differences are listed and the output is truncated to fit in a single comment.
+func GenerateMarkdown(result internal.ComparisonResult, DB1Name string, DB2Name string) string {
+	var sb strings.Builder
+
+	tables := tableDifferences(result)
+	sections := reportSections(result, DB1Name, DB2Name)
+
+	added, removed, modified := 0, 0, 0
+	for _, table := range tables {
+		for _, column := range table.Columns {
+			switch column.Type {
+			case internal.ChangeAdded:
+				added++
+			case internal.ChangeRemoved:
+				removed++
+			default:
+				modified++
+			}
+		}
+	}
+
+	fmt.Fprintf(&sb, "## Database comparison: %s vs %s\n\n", markdownCell(DB1Name), markdownCell(DB2Name))
+	sb.WriteString(markdownRow("Difference", "Count"))
+	sb.WriteString("| --- | ---: |\n")
+	sb.WriteString(markdownRow("Tables with column differences", fmt.Sprint(len(tables))))
+	sb.WriteString(markdownRow(fmt.Sprintf("Columns only in %s", DB1Name), fmt.Sprint(added)))
+	sb.WriteString(markdownRow(fmt.Sprintf("Columns only in %s", DB2Name), fmt.Sprint(removed)))
+	sb.WriteString(markdownRow("Modified columns", fmt.Sprint(modified)))
+	for _, section := range sections {
+		sb.WriteString(markdownRow(section.Name, fmt.Sprint(section.FlaggedRows())))
+	}
+	sb.WriteString("\n")
+
+	blocks := []markdownBlock{}
+	for _, table := range tables {
+		block := markdownBlock{
+			Summary: fmt.Sprintf("<code>%s</code> (%d columns)", markdownCell(table.TableName), len(table.Columns)),
+			Headers: []string{"Column", "Change", "Attribute", DB1Name, DB2Name},
+		}
+		for _, column := range table.Columns {
+			block.Rows = append(block.Rows, markdownColumnRows(column)...)
+		}
+		blocks = append(blocks, block)
+	}
+
+	for _, section := range sections {
+		block := markdownBlock{Headers: section.Headers}
+		for i, row := range section.Rows {
+			if section.Flagged[i] {
+				block.Rows = append(block.Rows, row)
+			}
+		}
+
+		if len(block.Rows) == 0 {
+			continue
+		}
+		block.Summary = fmt.Sprintf("%s (%d)", markdownCell(section.Name), len(block.Rows))
+		if section.Note != "" {
+			block.Summary = fmt.Sprintf("%s (%d, %s)", markdownCell(section.Name), len(block.Rows), markdownCell(section.Note))
+		}
+		blocks = append(blocks, block)
+	}
+
+	if len(blocks) == 0 {
+		sb.WriteString("No differences found.\n")
+		return sb.String()
+	}
+
+	limit := markdownCommentLimit - markdownReserve
+	omitted := 0
+	for _, block := range blocks {
+		header := fmt.Sprintf("<details>\n<summary>%s</summary>\n\n%s|%s\n", block.Summary, markdownRow(block.Headers...), strings.Repeat(" --- |", len(block.Headers)))
+		if sb.Len()+len(header) > limit {
+			omitted += len(block.Rows)
+			continue
+		}
+		sb.WriteString(header)
+
+		blockOmitted := 0
+		for _, row := range block.Rows {
+			line := markdownRow(row...)
+			if blockOmitted > 0 || sb.Len()+len(line) > limit {
+				blockOmitted++
+				continue
+			}
+			sb.WriteString(line)
+		}
+
+		if blockOmitted > 0 {
+			fmt.Fprintf(&sb, "\n_%d more not shown_\n", blockOmitted)
+			omitted += blockOmitted
+		}
+		sb.WriteString("\n</details>\n\n")
+	}
+
+	if omitted > 0 {
+		fmt.Fprintf(&sb, "> **%d more differences not shown** to stay under the comment size limit. Use `--format excel` or `--format html` for the full report.\n", omitted)
+	}
+
+	return sb.String()
+}
+
+// SaveAsMarkdown writes the comparison result as a markdown file.
+func SaveAsMarkdown(result internal.ComparisonResult, DB1Name string, DB2Name string, output string) error {
+	return os.WriteFile(output, []byte(GenerateMarkdown(result, DB1Name, DB2Name)), 0o644)
+}
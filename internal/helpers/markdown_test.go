@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+func TestMarkdownCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: " "},
+		{value: "orders", want: "orders"},
+		{value: "a|b", want: `a\|b`},
+		{value: "line 1\nline 2\r\nline 3", want: "line 1<br>line 2<br>line 3"},
+		{value: "<script>", want: "&lt;script&gt;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := markdownCell(tt.value); got != tt.want {
+				t.Errorf("markdownCell(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateMarkdown(t *testing.T) {
+	equal := map[string]map[string]internal.ColumnData{"orders": {"id": testColumn("orders", "id", 1)}}
+	noDifferences, _ := internal.CompareTables(equal, equal, internal.CompareOptions{})
+
+	DB1Tables := map[string]map[string]internal.ColumnData{"orders": {}}
+	DB2Tables := map[string]map[string]internal.ColumnData{"orders": {}}
+	for i := 1; i <= 3000; i++ {
+		col := testColumn("orders", fmt.Sprintf("column_%d", i), i)
+		DB2Tables["orders"][col.ColumnName] = col
+		col.DataType = "bigint"
+		DB1Tables["orders"][col.ColumnName] = col
+	}
+	large, _ := internal.CompareTables(DB1Tables, DB2Tables, internal.CompareOptions{})
+
+	tests := []struct {
+		name     string
+		result   internal.ComparisonResult
+		contains []string
+	}{
+		{
+			name:     "no differences",
+			result:   noDifferences,
+			contains: []string{"## Database comparison: DB1 vs DB2\n", "| Tables with column differences | 0 |\n", "No differences found.\n"},
+		},
+		{
+			name:   "column and table differences",
+			result: testResult(),
+			contains: []string{
+				"| Tables with column differences | 1 |\n",
+				"| Missing tables | 2 |\n",
+				"<summary><code>orders</code> (3 columns)</summary>",
+				"| total | modified | Data Type | bigint | integer |\n",
+				"| note | added | Data Type | integer |   |\n",
+				"| status | removed | Data Type |   | integer |\n",
+				"<summary>Missing tables (2)</summary>",
+				"| customers | Present |   |\n",
+			},
+		},
+		{
+			name:     "truncated",
+			result:   large,
+			contains: []string{"more not shown_", "more differences not shown** to stay under the comment size limit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GenerateMarkdown(tt.result, "DB1", "DB2")
+			if len(got) > markdownCommentLimit {
+				t.Errorf("GenerateMarkdown() length = %d, want at most %d", len(got), markdownCommentLimit)
+			}
+
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("GenerateMarkdown() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
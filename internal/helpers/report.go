@@ -0,0 +1,246 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+// reportSection is a table of differences shared by the text based report formats.
+type reportSection struct {
+	Name    string
+	Note    string
+	Headers []string
+	Rows    [][]string
+	// Flagged marks the rows that should be highlighted
+	Flagged []bool
+	// Paired is set when the last two columns hold the values found in each database
+	Paired bool
+	// Changes holds how each row of a paired section differs. A value that does not exist in one of
+	// the databases is left empty, so it is not taken for a null value.
+	Changes []internal.ChangeType
+	// Streamed is set when the rows were left out to be written straight from the comparison result,
+	// see rowDifference
+	Streamed bool
+}
+
+// tableDifference holds the column changes of a table present in both databases.
+type tableDifference struct {
+	TableName string
+	Columns   []internal.Change
+}
+
+// differenceSection flags the rows whose last two values, the values found in each database, differ.
+// changes holds how each row differs.
+func differenceSection(name string, headers []string, rows [][]string, changes []internal.ChangeType) reportSection {
+	flagged := make([]bool, len(rows))
+	for i, row := range rows {
+		flagged[i] = row[len(row)-2] != row[len(row)-1]
+	}
+
+	return reportSection{Name: name, Headers: headers, Rows: rows, Flagged: flagged, Paired: true, Changes: changes}
+}
+
+// tableDifferences groups the column changes by table. Changes are already sorted by table.
+func tableDifferences(result internal.ComparisonResult) []tableDifference {
+	differences := []tableDifference{}
+
+	for _, change := range result.ColumnChanges() {
+		if len(differences) == 0 || differences[len(differences)-1].TableName != change.Table {
+			differences = append(differences, tableDifference{TableName: change.Table})
+		}
+
+		table := &differences[len(differences)-1]
+		table.Columns = append(table.Columns, change)
+	}
+
+	return differences
+}
+
+// rowDifferenceHeaders are the headers of the Row Differences section.
+func rowDifferenceHeaders(DB1Name, DB2Name string) []string {
+	return []string{"Table Name", "Key", "Changed Columns", DB1Name, DB2Name}
+}
+
+// rowDifference returns the row of the Row Differences section for a difference of the table.
+func rowDifference(table internal.TableDataComparison, diff internal.RowDifference) []string {
+	DB1Row, DB2Row := "", ""
+	if diff.DB1 != nil {
+		DB1Row = diff.DB1.String(table.Columns)
+	}
+	if diff.DB2 != nil {
+		DB2Row = diff.DB2.String(table.Columns)
+	}
+
+	return []string{diff.TableName, diff.Key, strings.Join(diff.Columns, ", "), DB1Row, DB2Row}
+}
+
+// reportSections returns every difference besides the column differences as tables, in the
+// same order as the sheets of the Excel report.
+func reportSections(result internal.ComparisonResult, DB1Name, DB2Name string) []reportSection {
+	return buildReportSections(result, DB1Name, DB2Name, false)
+}
+
+// buildReportSections returns the report sections. When streamRows is set the Row Differences
+// section is returned without rows, so large data comparisons are not rendered in memory.
+func buildReportSections(result internal.ComparisonResult, DB1Name, DB2Name string, streamRows bool) []reportSection {
+	sections := []reportSection{}
+
+	rows, changes := [][]string{}, []internal.ChangeType{}
+	for _, change := range result.Changes {
+		if change.Kind != internal.ObjectTable {
+			continue
+		}
+
+		if change.Type == internal.ChangeAdded {
+			rows = append(rows, []string{change.Table, "Present", ""})
+		} else {
+			rows = append(rows, []string{change.Table, "", "Present"})
+		}
+		changes = append(changes, change.Type)
+	}
+	sections = append(sections, differenceSection("Missing tables", []string{"Table Name", DB1Name, DB2Name}, rows, changes))
+
+	rows, changes = [][]string{}, []internal.ChangeType{}
+	for _, v := range result.PolicyDifferences {
+		rows = append(rows, []string{string(v.Severity), v.TableName, v.PolicyName, v.Attribute, v.DB1, v.DB2})
+		changes = append(changes, v.Change)
+	}
+	sections = append(sections, differenceSection("Row Level Security", []string{"Severity", "Table Name", "Policy Name", "Attribute", DB1Name, DB2Name}, rows, changes))
+
+	rows, changes = [][]string{}, []internal.ChangeType{}
+	for _, v := range result.PartitionDiffs {
+		rows = append(rows, []string{v.TableName, v.Attribute, v.DB1, v.DB2})
+		changes = append(changes, v.Change)
+	}
+	sections = append(sections, differenceSection("Partition Differences", []string{"Table Name", "Attribute", DB1Name, DB2Name}, rows, changes))
+
+	partitions := reportSection{
+		Name: "Partitions",
+		Headers: []string{"Table Name", "Strategy", "Partition Key", fmt.Sprintf("Partitions in %s", DB1Name), fmt.Sprintf("Partitions in %s", DB2Name),
+			fmt.Sprintf("Bounds in %s", DB1Name), fmt.Sprintf("Bounds in %s", DB2Name)},
+	}
+	for _, v := range result.Partitions {
+		partitions.Rows = append(partitions.Rows, []string{v.TableName, v.DB1.Strategy, v.DB1.Key, fmt.Sprint(len(v.DB1.Bounds)), fmt.Sprint(len(v.DB2.Bounds)), v.DB1.BoundsSummary(), v.DB2.BoundsSummary()})
+		partitions.Flagged = append(partitions.Flagged, v.DB1.BoundsSummary() != v.DB2.BoundsSummary())
+	}
+	sections = append(sections, partitions)
+
+	if result.CommentDifferences != nil {
+		rows, changes = [][]string{}, []internal.ChangeType{}
+		for _, v := range result.CommentDifferences {
+			rows = append(rows, []string{string(v.Severity), v.ObjectType, v.ObjectName, v.DB1, v.DB2})
+			changes = append(changes, v.Change)
+		}
+		sections = append(sections, differenceSection("Comments", []string{"Severity", "Object Type", "Object Name", DB1Name, DB2Name}, rows, changes))
+	}
+
+	if result.Privileges != nil {
+		rows, changes = [][]string{}, []internal.ChangeType{}
+		for _, v := range result.Privileges.Privileges {
+			rows = append(rows, []string{v.Grantee, v.ObjectType, v.ObjectName, v.Privilege, v.DB1, v.DB2})
+			changes = append(changes, v.Change)
+		}
+		sections = append(sections, differenceSection("Privileges", []string{"Grantee", "Object Type", "Object Name", "Privilege", DB1Name, DB2Name}, rows, changes))
+
+		rows, changes = [][]string{}, []internal.ChangeType{}
+		for _, v := range result.Privileges.Owners {
+			rows = append(rows, []string{v.ObjectType, v.ObjectName, v.DB1, v.DB2})
+			changes = append(changes, v.Change)
+		}
+		sections = append(sections, differenceSection("Ownership", []string{"Object Type", "Object Name", DB1Name, DB2Name}, rows, changes))
+
+		rows, changes = [][]string{}, []internal.ChangeType{}
+		for _, v := range result.Privileges.Roles {
+			rows = append(rows, []string{v.RoleName, v.Attribute, v.DB1, v.DB2})
+			changes = append(changes, v.Change)
+		}
+		sections = append(sections, differenceSection("Roles", []string{"Role", "Attribute", DB1Name, DB2Name}, rows, changes))
+	}
+
+	if result.TableStats != nil {
+		stats := reportSection{
+			Name: "Table Statistics",
+			Headers: []string{
+				"Table Name",
+				fmt.Sprintf("Estimated Rows (%s)", DB1Name), fmt.Sprintf("Estimated Rows (%s)", DB2Name),
+				fmt.Sprintf("Exact Rows (%s)", DB1Name), fmt.Sprintf("Exact Rows (%s)", DB2Name),
+				"Row Difference %",
+				fmt.Sprintf("Total Size (%s)", DB1Name), fmt.Sprintf("Total Size (%s)", DB2Name),
+				"Size Difference %",
+			},
+		}
+		for _, v := range result.TableStats {
+			stats.Rows = append(stats.Rows, []string{
+				v.TableName,
+				fmt.Sprint(v.DB1.EstimatedRows), fmt.Sprint(v.DB2.EstimatedRows),
+				exactRows(v.DB1), exactRows(v.DB2),
+				fmt.Sprintf("%.2f", v.RowDiffPercent),
+				fmt.Sprint(v.DB1.TotalSize), fmt.Sprint(v.DB2.TotalSize),
+				fmt.Sprintf("%.2f", v.SizeDiffPercent),
+			})
+			stats.Flagged = append(stats.Flagged, v.Flagged)
+		}
+		sections = append(sections, stats)
+	}
+
+	if result.ProfileDifferences != nil {
+		rows, changes = [][]string{}, []internal.ChangeType{}
+		for _, v := range result.ProfileDifferences {
+			rows = append(rows, []string{v.TableName, v.ColumnName, v.Metric, v.DB1, v.DB2})
+			changes = append(changes, v.Change)
+		}
+		sections = append(sections, differenceSection("Column Profiles", []string{"Table Name", "Column Name", "Metric", DB1Name, DB2Name}, rows, changes))
+	}
+
+	if result.Data != nil {
+		data := reportSection{
+			Name:    "Data Comparison",
+			Note:    fmt.Sprintf("Sample: %s, Seed: %d", result.Data.Sample, result.Data.Seed),
+			Headers: []string{"Table Name", "Rows Compared", "Mismatches", "Mismatch Rate %", "95% Confidence Interval", "Filter", "Notes"},
+		}
+
+		rows, changes = [][]string{}, []internal.ChangeType{}
+		for _, v := range result.Data.Tables {
+			data.Rows = append(data.Rows, []string{v.TableName, fmt.Sprint(v.RowsCompared), fmt.Sprint(v.Mismatches), fmt.Sprintf("%.4f", v.MismatchRate), fmt.Sprintf("%.4f - %.4f", v.RateLow, v.RateHigh), v.Filter, v.Skipped})
+			data.Flagged = append(data.Flagged, v.Mismatches > 0)
+
+			if streamRows {
+				continue
+			}
+
+			for _, diff := range v.Differences {
+				rows = append(rows, rowDifference(v, diff))
+				changes = append(changes, diff.Change())
+			}
+		}
+
+		rowDifferences := differenceSection("Row Differences", rowDifferenceHeaders(DB1Name, DB2Name), rows, changes)
+		rowDifferences.Streamed = streamRows
+		sections = append(sections, data, rowDifferences)
+	}
+
+	if result.Settings != nil {
+		rows, changes = [][]string{}, []internal.ChangeType{}
+		for _, v := range result.Settings.Differences {
+			rows = append(rows, []string{v.Name, v.Category, v.DB1, v.DB2})
+			changes = append(changes, v.Change)
+		}
+		sections = append(sections, differenceSection("Settings", []string{"Setting", "Category", DB1Name, DB2Name}, rows, changes))
+	}
+
+	return sections
+}
+
+// FlaggedRows returns the number of highlighted rows of the section. It is exported for the HTML template.
+func (s reportSection) FlaggedRows() int {
+	count := 0
+	for _, flagged := range s.Flagged {
+		if flagged {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,113 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+// testColumn returns a nullable integer column without a default.
+func testColumn(table, name string, position int) internal.ColumnData {
+	return internal.ColumnData{
+		TableName: table, ColumnName: name, OrdinalPosition: position, DataType: "integer", ColumnDefault: "Null",
+		IsNullable: "YES", NumericPrecision: 32, IntervalType: "Null", CollationName: "Null", ElementType: "Null",
+		IsIdentity: "NO", IdentityGeneration: "Null", IsGenerated: "NEVER", GenerationExpression: "Null",
+	}
+}
+
+// testResult returns a comparison in which customers only exists in the first database, invoices only
+// in the second one, and orders has an added, a removed and a modified column.
+func testResult() internal.ComparisonResult {
+	total := testColumn("orders", "total", 2)
+	total.DataType = "bigint"
+
+	DB1Tables := map[string]map[string]internal.ColumnData{
+		"customers": {"id": testColumn("customers", "id", 1)},
+		"orders":    {"id": testColumn("orders", "id", 1), "total": total, "note": testColumn("orders", "note", 3)},
+	}
+	DB2Tables := map[string]map[string]internal.ColumnData{
+		"invoices": {"id": testColumn("invoices", "id", 1)},
+		"orders":   {"id": testColumn("orders", "id", 1), "total": testColumn("orders", "total", 2), "status": testColumn("orders", "status", 3)},
+	}
+
+	result, _ := internal.CompareTables(DB1Tables, DB2Tables, internal.CompareOptions{})
+	result.DB1Tables, result.DB2Tables = DB1Tables, DB2Tables
+
+	return result
+}
+
+func TestTableDifferences(t *testing.T) {
+	got := []string{}
+	for _, table := range tableDifferences(testResult()) {
+		for _, column := range table.Columns {
+			got = append(got, table.TableName+"."+column.Column+" "+string(column.Type))
+		}
+	}
+
+	want := []string{"orders.total modified", "orders.note added", "orders.status removed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tableDifferences() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildReportSections(t *testing.T) {
+	withSettings := testResult()
+	withSettings.Settings = &internal.SettingsComparison{Differences: []internal.SettingDifference{
+		{Name: "Server Version", Category: "Server", DB1: "16.2", DB2: "15.6", Change: internal.ChangeModified},
+		{Name: "jit", Category: "Query Tuning", DB1: "on", Change: internal.ChangeAdded},
+	}}
+	noSettings := testResult()
+	noSettings.Settings = &internal.SettingsComparison{}
+
+	tests := []struct {
+		name    string
+		result  internal.ComparisonResult
+		section string
+		rows    [][]string
+		flagged []bool
+		changes []internal.ChangeType
+	}{
+		{
+			name:    "missing tables",
+			result:  testResult(),
+			section: "Missing tables",
+			rows:    [][]string{{"customers", "Present", ""}, {"invoices", "", "Present"}},
+			flagged: []bool{true, true},
+			changes: []internal.ChangeType{internal.ChangeAdded, internal.ChangeRemoved},
+		},
+		{
+			name:    "settings",
+			result:  withSettings,
+			section: "Settings",
+			rows:    [][]string{{"Server Version", "Server", "16.2", "15.6"}, {"jit", "Query Tuning", "on", ""}},
+			flagged: []bool{true, true},
+			changes: []internal.ChangeType{internal.ChangeModified, internal.ChangeAdded},
+		},
+		{
+			name:    "no settings difference",
+			result:  noSettings,
+			section: "Settings",
+			rows:    [][]string{},
+			flagged: []bool{},
+			changes: []internal.ChangeType{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, section := range buildReportSections(tt.result, "DB1", "DB2", false) {
+				if section.Name != tt.section {
+					continue
+				}
+
+				if !reflect.DeepEqual(section.Rows, tt.rows) || !reflect.DeepEqual(section.Flagged, tt.flagged) || !reflect.DeepEqual(section.Changes, tt.changes) {
+					t.Errorf("section %s = %q %v %v, want %q %v %v", tt.section, section.Rows, section.Flagged, section.Changes, tt.rows, tt.flagged, tt.changes)
+				}
+				return
+			}
+
+			t.Errorf("section %s not found", tt.section)
+		})
+	}
+}
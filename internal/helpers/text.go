@@ -0,0 +1,153 @@
+package helpers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+	"github.com/CDavidSV/go-dbcompare/internal/config"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// IsTerminal reports whether the file is an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// WriteText writes the differences as a unified diff per table, with the second database (target) as
+// the old side and the first one (source) as the new side, so objects only in the first database are
+// added. Colors are only used when w is a terminal.
+func WriteText(w io.Writer, result internal.ComparisonResult, DB1Name string, DB2Name string) error {
+	renderer := lipgloss.NewRenderer(w)
+	removedStyle := config.ErrorStyle.Renderer(renderer)
+	addedStyle := config.SuccessStyle.Renderer(renderer)
+	changedStyle := config.WarningStyle.Renderer(renderer)
+	hunkStyle := config.InfoStyle.Renderer(renderer)
+	titleStyle := config.TitleStyle.Renderer(renderer)
+
+	var sb strings.Builder
+	line := func(style lipgloss.Style, format string, args ...any) {
+		sb.WriteString(style.Render(fmt.Sprintf(format, args...)))
+		sb.WriteString("\n")
+	}
+
+	line(titleStyle, "--- %s", DB2Name)
+	line(titleStyle, "+++ %s", DB1Name)
+
+	tables := tableDifferences(result)
+	added, removed, modified := 0, 0, 0
+
+	for _, table := range tables {
+		line(hunkStyle, "@@ table %s @@", table.TableName)
+
+		for _, column := range table.Columns {
+			dataType, _ := column.Attribute("Data Type")
+
+			switch column.Type {
+			case internal.ChangeAdded:
+				added++
+				line(addedStyle, "+ column %s %s", column.Column, dataType.DB1())
+			case internal.ChangeRemoved:
+				removed++
+				line(removedStyle, "- column %s %s", column.Column, dataType.DB2())
+			default:
+				modified++
+				line(changedStyle, "~ column %s", column.Column)
+				for _, attr := range column.ChangedAttributes() {
+					line(removedStyle, "-     %s: %s", attr.Name, attr.DB2())
+					line(addedStyle, "+     %s: %s", attr.Name, attr.DB1())
+				}
+			}
+		}
+	}
+
+	if len(result.MissingTablesInDB1)+len(result.MissingTablesInDB2) > 0 {
+		line(hunkStyle, "@@ tables @@")
+		for _, change := range result.Changes {
+			switch {
+			case change.Kind != internal.ObjectTable:
+				continue
+			case change.Type == internal.ChangeAdded:
+				line(addedStyle, "+ table %s", change.Table)
+			default:
+				line(removedStyle, "- table %s", change.Table)
+			}
+		}
+	}
+
+	other := 0
+	for _, section := range reportSections(result, DB1Name, DB2Name) {
+		if section.Name == "Missing tables" || section.FlaggedRows() == 0 {
+			continue
+		}
+
+		if section.Note != "" {
+			line(hunkStyle, "@@ %s (%s) @@", strings.ToLower(section.Name), section.Note)
+		} else {
+			line(hunkStyle, "@@ %s @@", strings.ToLower(section.Name))
+		}
+
+		for i, row := range section.Rows {
+			if !section.Flagged[i] {
+				continue
+			}
+			other++
+
+			if !section.Paired {
+				values := []string{}
+				for j, v := range row {
+					if v != "" {
+						values = append(values, fmt.Sprintf("%s: %s", section.Headers[j], v))
+					}
+				}
+				line(changedStyle, "~ %s", strings.Join(values, ", "))
+				continue
+			}
+
+			keys := []string{}
+			for _, v := range row[:len(row)-2] {
+				if v != "" {
+					keys = append(keys, v)
+				}
+			}
+			key := strings.Join(keys, " / ")
+
+			if section.Changes[i] != internal.ChangeAdded {
+				line(removedStyle, "- %s: %s", key, row[len(row)-1])
+			}
+			if section.Changes[i] != internal.ChangeRemoved {
+				line(addedStyle, "+ %s: %s", key, row[len(row)-2])
+			}
+		}
+	}
+
+	sb.WriteString("\n")
+	summary := fmt.Sprintf("%d tables with column differences, %d columns only in %s, %d columns only in %s, %d modified columns, %d tables only in %s, %d tables only in %s, %d other differences",
+		len(tables), added, DB1Name, removed, DB2Name, modified, len(result.MissingTablesInDB2), DB1Name, len(result.MissingTablesInDB1), DB2Name, other)
+	if len(tables)+len(result.MissingTablesInDB1)+len(result.MissingTablesInDB2)+other == 0 {
+		line(addedStyle, "No differences found")
+	} else {
+		line(titleStyle, "%s", summary)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// SaveAsText writes the differences as a unified diff to the output file, without colors.
+func SaveAsText(result internal.ComparisonResult, DB1Name string, DB2Name string, output string) error {
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return WriteText(file, result, DB1Name, DB2Name)
+}
@@ -0,0 +1,80 @@
+package helpers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/CDavidSV/go-dbcompare/internal"
+)
+
+func TestWriteText(t *testing.T) {
+	equal := map[string]map[string]internal.ColumnData{"orders": {"id": testColumn("orders", "id", 1)}}
+	noDifferences, _ := internal.CompareTables(equal, equal, internal.CompareOptions{})
+
+	withSettings := testResult()
+	withSettings.Settings = &internal.SettingsComparison{Differences: []internal.SettingDifference{
+		{Name: "work_mem", Category: "Resource Usage", DB1: "4MB", DB2: "64MB", Change: internal.ChangeModified},
+		{Name: "jit", Category: "Query Tuning", DB1: "on", Change: internal.ChangeAdded},
+	}}
+
+	columns := []string{
+		"--- DB2",
+		"+++ DB1",
+		"@@ table orders @@",
+		"~ column total",
+		"-     Data Type: integer",
+		"+     Data Type: bigint",
+		"+ column note integer",
+		"- column status integer",
+		"@@ tables @@",
+		"+ table customers",
+		"- table invoices",
+	}
+
+	tests := []struct {
+		name   string
+		result internal.ComparisonResult
+		want   []string
+	}{
+		{
+			name:   "no differences",
+			result: noDifferences,
+			want:   []string{"--- DB2", "+++ DB1", "", "No differences found", ""},
+		},
+		{
+			name:   "columns and tables",
+			result: testResult(),
+			want: append(columns[:len(columns):len(columns)],
+				"",
+				"1 tables with column differences, 1 columns only in DB1, 1 columns only in DB2, 1 modified columns, 1 tables only in DB1, 1 tables only in DB2, 0 other differences",
+				""),
+		},
+		{
+			name:   "settings",
+			result: withSettings,
+			want: append(columns[:len(columns):len(columns)],
+				"@@ settings @@",
+				"- work_mem / Resource Usage: 64MB",
+				"+ work_mem / Resource Usage: 4MB",
+				"+ jit / Query Tuning: on",
+				"",
+				"1 tables with column differences, 1 columns only in DB1, 1 columns only in DB2, 1 modified columns, 1 tables only in DB1, 1 tables only in DB2, 2 other differences",
+				""),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteText(&buf, tt.result, "DB1", "DB2"); err != nil {
+				t.Fatalf("WriteText() error = %v", err)
+			}
+
+			// A buffer is not a terminal, so the output has no colors
+			if want := strings.Join(tt.want, "\n"); buf.String() != want {
+				t.Errorf("WriteText() = %q, want %q", buf.String(), want)
+			}
+		})
+	}
+}
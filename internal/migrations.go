@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MigrationFormats are the migration tools the generated migration can be written for.
+var MigrationFormats = []string{"flyway", "liquibase-xml", "liquibase-yaml", "goose", "golang-migrate"}
+
+// MigrationFile is a file of a generated migration, relative to the output directory.
+type MigrationFile struct {
+	Name    string
+	Content string
+}
+
+var descriptionPattern = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// migrationDescription turns the description into a name usable in file names and changeset ids.
+func migrationDescription(description string) string {
+	description = strings.Trim(descriptionPattern.ReplaceAllString(strings.ToLower(description), "_"), "_")
+	if description == "" {
+		return "schema_migration"
+	}
+
+	return description
+}
+
+// cdata wraps the text in a CDATA section, splitting any "]]>" it contains.
+func cdata(text string) string {
+	return "<![CDATA[\n" + strings.ReplaceAll(text, "]]>", "]]]]><![CDATA[>") + "]]>"
+}
+
+// indent prefixes every non empty line of the text.
+func indent(text, prefix string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// gooseSection renders the statements for goose, which splits the section on semicolons. Statements
+// that contain a semicolon before their end are wrapped in StatementBegin and StatementEnd.
+func gooseSection(statements []DDLStatement) string {
+	var sb strings.Builder
+
+	for i, statement := range statements {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		if statement.Warning != "" {
+			fmt.Fprintf(&sb, "-- WARNING: %s\n", statement.Warning)
+		}
+
+		if strings.Contains(strings.TrimSuffix(statement.SQL, ";"), ";") {
+			fmt.Fprintf(&sb, "-- +goose StatementBegin\n%s\n-- +goose StatementEnd\n", statement.SQL)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "%s\n", statement.SQL)
+	}
+
+	return sb.String()
+}
+
+// FormatMigration returns the files of the migration in the layout expected by the migration tool.
+// up changes the target database to match the source, down reverts it.
+func FormatMigration(format, version, description string, up, down []DDLStatement) ([]MigrationFile, error) {
+	description = migrationDescription(description)
+	upSQL, downSQL := RenderDDL(up), RenderDDL(down)
+
+	switch format {
+	case "flyway":
+		// Undo migrations (U prefix) are only run by Flyway Teams, community editions ignore them
+		return []MigrationFile{
+			{Name: fmt.Sprintf("V%s__%s.sql", version, description), Content: upSQL},
+			{Name: fmt.Sprintf("U%s__%s.sql", version, description), Content: downSQL},
+		}, nil
+	case "golang-migrate":
+		return []MigrationFile{
+			{Name: fmt.Sprintf("%s_%s.up.sql", version, description), Content: upSQL},
+			{Name: fmt.Sprintf("%s_%s.down.sql", version, description), Content: downSQL},
+		}, nil
+	case "goose":
+		var sb strings.Builder
+		sb.WriteString("-- +goose Up\n")
+		sb.WriteString(gooseSection(up))
+		sb.WriteString("\n-- +goose Down\n")
+		sb.WriteString(gooseSection(down))
+
+		return []MigrationFile{{Name: fmt.Sprintf("%s_%s.sql", version, description), Content: sb.String()}}, nil
+	case "liquibase-xml":
+		var sb strings.Builder
+		sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<databaseChangeLog
+    xmlns="http://www.liquibase.org/xml/ns/dbchangelog"
+    xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+    xsi:schemaLocation="http://www.liquibase.org/xml/ns/dbchangelog http://www.liquibase.org/xml/ns/dbchangelog/dbchangelog-latest.xsd">
+`)
+		fmt.Fprintf(&sb, "    <changeSet id=\"%s_%s\" author=\"go-dbcompare\">\n", version, description)
+		fmt.Fprintf(&sb, "        <sql splitStatements=\"true\" endDelimiter=\";\">%s</sql>\n", cdata(upSQL))
+		fmt.Fprintf(&sb, "        <rollback>\n            <sql splitStatements=\"true\" endDelimiter=\";\">%s</sql>\n        </rollback>\n", cdata(downSQL))
+		sb.WriteString("    </changeSet>\n</databaseChangeLog>\n")
+
+		return []MigrationFile{{Name: fmt.Sprintf("%s_%s.xml", version, description), Content: sb.String()}}, nil
+	case "liquibase-yaml":
+		var sb strings.Builder
+		sb.WriteString("databaseChangeLog:\n  - changeSet:\n")
+		fmt.Fprintf(&sb, "      id: \"%s_%s\"\n      author: go-dbcompare\n", version, description)
+		sb.WriteString("      changes:\n        - sql:\n            splitStatements: true\n            endDelimiter: \";\"\n")
+		sb.WriteString("            sql: |\n" + indent(upSQL, "              "))
+		sb.WriteString("      rollback:\n        - sql:\n            splitStatements: true\n            endDelimiter: \";\"\n")
+		sb.WriteString("            sql: |\n" + indent(downSQL, "              "))
+
+		return []MigrationFile{{Name: fmt.Sprintf("%s_%s.yaml", version, description), Content: sb.String()}}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported migration format %s, expected one of: %s", format, strings.Join(MigrationFormats, ", "))
+}
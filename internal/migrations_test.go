@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrationDescription(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{description: "Add orders table", want: "add_orders_table"},
+		{description: "Sync: DB1 -> DB2!", want: "sync_db1_db2"},
+		{description: "  --  ", want: "schema_migration"},
+		{description: "", want: "schema_migration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			if got := migrationDescription(tt.description); got != tt.want {
+				t.Errorf("migrationDescription(%q) = %q, want %q", tt.description, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCdata(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "SELECT 1;\n", want: "<![CDATA[\nSELECT 1;\n]]>"},
+		{text: "SELECT ']]>';\n", want: "<![CDATA[\nSELECT ']]]]><![CDATA[>';\n]]>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := cdata(tt.text); got != tt.want {
+				t.Errorf("cdata(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGooseSection(t *testing.T) {
+	tests := []struct {
+		name       string
+		statements []DDLStatement
+		want       string
+	}{
+		{
+			name:       "single statements",
+			statements: []DDLStatement{{SQL: "DROP TABLE a;"}, {SQL: "DROP TABLE b;", Warning: "dropping table b deletes its data"}},
+			want:       "DROP TABLE a;\n\n-- WARNING: dropping table b deletes its data\nDROP TABLE b;\n",
+		},
+		{
+			name:       "statement with semicolons",
+			statements: []DDLStatement{{SQL: "-- \"ALTER TABLE a\": set generation expression to (x; y)"}, {SQL: "DROP TABLE a;"}},
+			want:       "-- +goose StatementBegin\n-- \"ALTER TABLE a\": set generation expression to (x; y)\n-- +goose StatementEnd\n\nDROP TABLE a;\n",
+		},
+		{
+			name:       "no statements",
+			statements: []DDLStatement{},
+			want:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gooseSection(tt.statements); got != tt.want {
+				t.Errorf("gooseSection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatMigration(t *testing.T) {
+	up := []DDLStatement{{SQL: `CREATE INDEX IF NOT EXISTS orders_total_idx ON public.orders USING btree (total);`}}
+	down := []DDLStatement{{SQL: `DROP INDEX IF EXISTS orders_total_idx;`}}
+
+	tests := []struct {
+		format   string
+		names    []string
+		contains []string
+		wantErr  bool
+	}{
+		{
+			format:   "flyway",
+			names:    []string{"V20240101120000__index_orders.sql", "U20240101120000__index_orders.sql"},
+			contains: []string{RenderDDL(up), RenderDDL(down)},
+		},
+		{
+			format:   "golang-migrate",
+			names:    []string{"20240101120000_index_orders.up.sql", "20240101120000_index_orders.down.sql"},
+			contains: []string{RenderDDL(up), RenderDDL(down)},
+		},
+		{
+			format:   "goose",
+			names:    []string{"20240101120000_index_orders.sql"},
+			contains: []string{"-- +goose Up\n" + gooseSection(up) + "\n-- +goose Down\n" + gooseSection(down)},
+		},
+		{
+			format:   "liquibase-xml",
+			names:    []string{"20240101120000_index_orders.xml"},
+			contains: []string{`<changeSet id="20240101120000_index_orders" author="go-dbcompare">`, "<rollback>\n            <sql splitStatements=\"true\" endDelimiter=\";\"><![CDATA[\nDROP INDEX"},
+		},
+		{
+			format:   "liquibase-yaml",
+			names:    []string{"20240101120000_index_orders.yaml"},
+			contains: []string{`id: "20240101120000_index_orders"`, "      rollback:\n        - sql:\n            splitStatements: true\n            endDelimiter: \";\"\n            sql: |\n              DROP INDEX"},
+		},
+		{format: "sqitch", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			files, err := FormatMigration(tt.format, "20240101120000", "Index orders", up, down)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatMigration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			names := []string{}
+			content := ""
+			for _, file := range files {
+				names = append(names, file.Name)
+				content += file.Content
+			}
+
+			if len(tt.names) > 0 && !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("FormatMigration() files = %q, want %q", names, tt.names)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(content, want) {
+					t.Errorf("FormatMigration() content = %q, want it to contain %q", content, want)
+				}
+			}
+		})
+	}
+}
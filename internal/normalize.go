@@ -0,0 +1,263 @@
+package internal
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Comparator configures how the values of a column are normalized before being compared
+// in the row comparison.
+type Comparator struct {
+	// NumericTolerance is the maximum absolute difference for two numbers to be equal
+	NumericTolerance *float64 `json:"numeric_tolerance,omitempty"`
+	// Timestamp compares values as instants, ignoring the time zone they are displayed in
+	Timestamp bool `json:"timestamp,omitempty"`
+	// TimestampTruncate truncates timestamps to the given unit before comparing them
+	TimestampTruncate string `json:"timestamp_truncate,omitempty"`
+	Trim              bool   `json:"trim,omitempty"`
+	CaseInsensitive   bool   `json:"case_insensitive,omitempty"`
+	// JSON compares values semantically, ignoring key order and whitespace
+	JSON bool `json:"json,omitempty"`
+	// UnorderedArray compares arrays ignoring the order of their elements
+	UnorderedArray bool `json:"unordered_array,omitempty"`
+}
+
+// Comparators are keyed by "table.column". A "*.column" key applies to the column in every table.
+type Comparators map[string]Comparator
+
+var truncateUnits = map[string]time.Duration{
+	"microsecond": time.Microsecond,
+	"millisecond": time.Millisecond,
+	"second":      time.Second,
+	"minute":      time.Minute,
+	"hour":        time.Hour,
+	"day":         24 * time.Hour,
+}
+
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+func (c Comparators) Validate() error {
+	for key, comparator := range c {
+		if !strings.Contains(key, ".") {
+			return fmt.Errorf("comparator key must be in the format table.column. Got: %s", key)
+		}
+
+		if comparator.TimestampTruncate != "" {
+			if _, ok := truncateUnits[comparator.TimestampTruncate]; !ok {
+				return fmt.Errorf("invalid timestamp_truncate unit for %s: %s", key, comparator.TimestampTruncate)
+			}
+		}
+
+		if comparator.NumericTolerance != nil && *comparator.NumericTolerance < 0 {
+			return fmt.Errorf("numeric_tolerance for %s must not be negative", key)
+		}
+	}
+
+	return nil
+}
+
+// For returns the comparator of the column, preferring a table specific one over a wildcard.
+func (c Comparators) For(tableName, columnName string) (Comparator, bool) {
+	if comparator, ok := c[tableName+"."+columnName]; ok {
+		return comparator, true
+	}
+
+	comparator, ok := c["*."+columnName]
+	return comparator, ok
+}
+
+func parseTimestamp(value string) (time.Time, bool) {
+	for _, layout := range timestampLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t.UTC(), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// parseArray splits a one dimensional array literal such as {a,"b c",NULL} into its elements. An
+// unquoted NULL is a null element, a quoted "NULL" is the text.
+func parseArray(value string) ([]sql.NullString, bool) {
+	if !strings.HasPrefix(value, "{") || !strings.HasSuffix(value, "}") {
+		return nil, false
+	}
+
+	inner := value[1 : len(value)-1]
+	if inner == "" {
+		return []sql.NullString{}, true
+	}
+
+	if strings.HasPrefix(inner, "{") {
+		// Multidimensional arrays are compared as they are
+		return nil, false
+	}
+
+	elements := []sql.NullString{}
+	var sb strings.Builder
+	quoted, escaped, wasQuoted := false, false, false
+
+	element := func() sql.NullString {
+		if !wasQuoted && strings.EqualFold(sb.String(), "NULL") {
+			return sql.NullString{}
+		}
+
+		return sql.NullString{String: sb.String(), Valid: true}
+	}
+
+	for _, r := range inner {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+			wasQuoted = true
+		case r == ',' && !quoted:
+			elements = append(elements, element())
+			sb.Reset()
+			wasQuoted = false
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	elements = append(elements, element())
+
+	return elements, true
+}
+
+// sortArray orders the elements of an array, null elements first.
+func sortArray(elements []sql.NullString) {
+	sort.Slice(elements, func(i, j int) bool {
+		if elements[i].Valid != elements[j].Valid {
+			return !elements[i].Valid
+		}
+
+		return elements[i].String < elements[j].String
+	})
+}
+
+// jsonNumber is a JSON number in its exact canonical form, so numbers are compared by value without
+// being rounded to float64 and are not equal to a string with the same digits.
+type jsonNumber string
+
+// parseJSON decodes a JSON value with its numbers in their exact canonical form.
+func parseJSON(value string) (any, bool) {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, false
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		// Trailing data after the value
+		return nil, false
+	}
+
+	return canonicalJSON(v), true
+}
+
+func canonicalJSON(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if number, ok := new(big.Rat).SetString(string(v)); ok {
+			return jsonNumber(number.RatString())
+		}
+		return jsonNumber(v)
+	case map[string]any:
+		for key, item := range v {
+			v[key] = canonicalJSON(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = canonicalJSON(item)
+		}
+	}
+
+	return value
+}
+
+func (c Comparator) normalizeText(value string) string {
+	if c.Trim {
+		value = strings.TrimSpace(value)
+	}
+
+	if c.CaseInsensitive {
+		value = strings.ToLower(value)
+	}
+
+	return value
+}
+
+// Equal reports whether both values are equal once normalized.
+func (c Comparator) Equal(a, b sql.NullString) bool {
+	if !a.Valid || !b.Valid {
+		return a.Valid == b.Valid
+	}
+
+	DB1Value, DB2Value := c.normalizeText(a.String), c.normalizeText(b.String)
+	if DB1Value == DB2Value {
+		return true
+	}
+
+	if c.NumericTolerance != nil {
+		DB1Number, err1 := strconv.ParseFloat(DB1Value, 64)
+		DB2Number, err2 := strconv.ParseFloat(DB2Value, 64)
+		if err1 == nil && err2 == nil {
+			return math.Abs(DB1Number-DB2Number) <= *c.NumericTolerance
+		}
+	}
+
+	if c.Timestamp || c.TimestampTruncate != "" {
+		DB1Time, ok1 := parseTimestamp(DB1Value)
+		DB2Time, ok2 := parseTimestamp(DB2Value)
+		if ok1 && ok2 {
+			if unit, ok := truncateUnits[c.TimestampTruncate]; ok {
+				DB1Time, DB2Time = DB1Time.Truncate(unit), DB2Time.Truncate(unit)
+			}
+
+			return DB1Time.Equal(DB2Time)
+		}
+	}
+
+	if c.JSON {
+		DB1JSON, ok1 := parseJSON(DB1Value)
+		DB2JSON, ok2 := parseJSON(DB2Value)
+		if ok1 && ok2 {
+			return reflect.DeepEqual(DB1JSON, DB2JSON)
+		}
+	}
+
+	if c.UnorderedArray {
+		DB1Elements, ok1 := parseArray(DB1Value)
+		DB2Elements, ok2 := parseArray(DB2Value)
+		if ok1 && ok2 {
+			sortArray(DB1Elements)
+			sortArray(DB2Elements)
+
+			return reflect.DeepEqual(DB1Elements, DB2Elements)
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestComparatorEqual(t *testing.T) {
+	value := func(s string) sql.NullString {
+		return sql.NullString{String: s, Valid: true}
+	}
+	tolerance := 0.01
+
+	tests := []struct {
+		name       string
+		comparator Comparator
+		a, b       sql.NullString
+		want       bool
+	}{
+		{name: "equal text", a: value("a"), b: value("a"), want: true},
+		{name: "different text", a: value("a"), b: value("b"), want: false},
+		{name: "both null", a: sql.NullString{}, b: sql.NullString{}, want: true},
+		{name: "null and text", a: sql.NullString{}, b: value("Null"), want: false},
+		{name: "trim", comparator: Comparator{Trim: true}, a: value(" a "), b: value("a"), want: true},
+		{name: "case insensitive", comparator: Comparator{CaseInsensitive: true}, a: value("ABC"), b: value("abc"), want: true},
+		{name: "within tolerance", comparator: Comparator{NumericTolerance: &tolerance}, a: value("1.005"), b: value("1.01"), want: true},
+		{name: "outside tolerance", comparator: Comparator{NumericTolerance: &tolerance}, a: value("1.0"), b: value("1.02"), want: false},
+		{name: "timestamp time zones", comparator: Comparator{Timestamp: true}, a: value("2024-01-01 10:00:00+00"), b: value("2024-01-01 12:00:00+02"), want: true},
+		{name: "timestamp truncated", comparator: Comparator{TimestampTruncate: "second"}, a: value("2024-01-01 10:00:00.123"), b: value("2024-01-01 10:00:00.456"), want: true},
+		{name: "timestamp different", comparator: Comparator{Timestamp: true}, a: value("2024-01-01 10:00:00+00"), b: value("2024-01-01 10:00:01+00"), want: false},
+		{name: "json key order", comparator: Comparator{JSON: true}, a: value(`{"a": 1, "b": [1, 2]}`), b: value(`{"b":[1,2],"a":1}`), want: true},
+		{name: "json number notation", comparator: Comparator{JSON: true}, a: value(`{"a": 1.0}`), b: value(`{"a": 1e0}`), want: true},
+		{name: "json large integers", comparator: Comparator{JSON: true}, a: value(`{"id": 9007199254740993}`), b: value(`{"id": 9007199254740992}`), want: false},
+		{name: "json number and string", comparator: Comparator{JSON: true}, a: value(`{"a": 1}`), b: value(`{"a": "1"}`), want: false},
+		{name: "json trailing data", comparator: Comparator{JSON: true}, a: value(`1 2`), b: value(`1`), want: false},
+		{name: "unordered array", comparator: Comparator{UnorderedArray: true}, a: value(`{b,a,"c d"}`), b: value(`{"c d",a,b}`), want: true},
+		{name: "unordered array null elements", comparator: Comparator{UnorderedArray: true}, a: value(`{NULL,a}`), b: value(`{a,null}`), want: true},
+		{name: "null element and text", comparator: Comparator{UnorderedArray: true}, a: value(`{NULL,a}`), b: value(`{a,"NULL"}`), want: false},
+		{name: "different arrays", comparator: Comparator{UnorderedArray: true}, a: value(`{a,b}`), b: value(`{a,a}`), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.comparator.Equal(tt.a, tt.b); got != tt.want {
+				t.Errorf("Equal(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArray(t *testing.T) {
+	value := func(s string) sql.NullString {
+		return sql.NullString{String: s, Valid: true}
+	}
+
+	tests := []struct {
+		literal string
+		want    []sql.NullString
+		ok      bool
+	}{
+		{literal: "{}", want: []sql.NullString{}, ok: true},
+		{literal: "{a,b}", want: []sql.NullString{value("a"), value("b")}, ok: true},
+		{literal: `{"a,b","c \"d\""}`, want: []sql.NullString{value("a,b"), value(`c "d"`)}, ok: true},
+		{literal: `{NULL,"NULL",""}`, want: []sql.NullString{{}, value("NULL"), value("")}, ok: true},
+		{literal: "{{1,2},{3,4}}", ok: false},
+		{literal: "a,b", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.literal, func(t *testing.T) {
+			got, ok := parseArray(tt.literal)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseArray(%q) = %v, %v, want %v, %v", tt.literal, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
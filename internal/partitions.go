@@ -0,0 +1,233 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// boundPattern matches the values a partition bound starts with: the lower bound of a range partition,
+// the values of a list partition or the remainder of a hash partition.
+var boundPattern = regexp.MustCompile(`^FOR VALUES (?:FROM \((.*?)\) TO|IN \((.*)\)|WITH \(modulus \d+, remainder (\d+)\))`)
+
+type PartitionedTableData struct {
+	TableName string
+	Strategy  string
+	Key       string
+	Bounds    []string
+}
+
+// PartitionSummary describes a partitioned table present in both databases. Partitions are
+// summarized by their count and bounds instead of being compared as individual tables.
+type PartitionSummary struct {
+	TableName string
+	DB1       PartitionedTableData
+	DB2       PartitionedTableData
+}
+
+type PartitionDifference struct {
+	TableName string
+	Attribute string
+	DB1       string
+	DB2       string
+	// Change is added when the bound only exists in the first database and removed when it only exists in the second one
+	Change ChangeType
+}
+
+// GetDBPartitionedTables returns every partitioned table in the public schema with its strategy,
+// partition key and the bounds of its direct partitions.
+func GetDBPartitionedTables(db *sql.DB) (map[string]PartitionedTableData, error) {
+	rows, err := db.Query(`SELECT
+		c.relname::text,
+		CASE p.partstrat WHEN 'r' THEN 'RANGE' WHEN 'l' THEN 'LIST' WHEN 'h' THEN 'HASH' ELSE p.partstrat::text END,
+		pg_get_partkeydef(c.oid),
+		pg_get_expr(child.relpartbound, child.oid)
+	FROM pg_partitioned_table p
+	INNER JOIN pg_class c ON c.oid = p.partrelid
+	INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_inherits i ON i.inhparent = c.oid
+	LEFT JOIN pg_class child ON child.oid = i.inhrelid
+	WHERE n.nspname = 'public' AND NOT c.relispartition`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := map[string]PartitionedTableData{}
+	for rows.Next() {
+		var table PartitionedTableData
+		var bound sql.NullString
+
+		err = rows.Scan(&table.TableName, &table.Strategy, &table.Key, &bound)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing, ok := tables[table.TableName]; ok {
+			table = existing
+		}
+
+		if bound.Valid {
+			table.Bounds = append(table.Bounds, bound.String)
+		}
+
+		tables[table.TableName] = table
+	}
+
+	for name, table := range tables {
+		sortBounds(table.Bounds)
+		tables[name] = table
+	}
+
+	return tables, rows.Err()
+}
+
+// boundValues splits the values a partition bound starts with. Commas inside quoted values are kept.
+func boundValues(bound string) []string {
+	match := boundPattern.FindStringSubmatch(bound)
+	if match == nil {
+		return nil
+	}
+	list := match[1] + match[2] + match[3]
+
+	values := []string{}
+	start, quoted := 0, false
+	for i, r := range list {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			values = append(values, strings.TrimSpace(list[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(values, strings.TrimSpace(list[start:]))
+}
+
+// compareBoundValue orders two values of a partition bound. MINVALUE and MAXVALUE go first and last,
+// numbers are compared by value and anything else, such as quoted dates, as text.
+func compareBoundValue(a, b string) int {
+	rank := func(v string) int {
+		switch v {
+		case "MINVALUE":
+			return -1
+		case "MAXVALUE":
+			return 1
+		}
+		return 0
+	}
+	if rank(a) != rank(b) {
+		return rank(a) - rank(b)
+	}
+
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(a, b)
+}
+
+// sortBounds orders the partition bounds by the values they start with, with the default partition last.
+func sortBounds(bounds []string) {
+	sort.SliceStable(bounds, func(i, j int) bool {
+		if bounds[i] == "DEFAULT" || bounds[j] == "DEFAULT" {
+			return bounds[j] == "DEFAULT" && bounds[i] != "DEFAULT"
+		}
+
+		a, b := boundValues(bounds[i]), boundValues(bounds[j])
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if c := compareBoundValue(a[k], b[k]); c != 0 {
+				return c < 0
+			}
+		}
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+
+		return bounds[i] < bounds[j]
+	})
+}
+
+// BoundsSummary returns the first and last partition bound, which is enough to
+// see the range covered by date based partitions without listing every partition.
+func (p PartitionedTableData) BoundsSummary() string {
+	switch len(p.Bounds) {
+	case 0:
+		return ""
+	case 1:
+		return p.Bounds[0]
+	default:
+		return fmt.Sprintf("%s ... %s", p.Bounds[0], p.Bounds[len(p.Bounds)-1])
+	}
+}
+
+// ComparePartitions compares the strategy and key of the partitioned tables present in both databases.
+// When strictBounds is set, every partition bound missing on either side is reported as a difference.
+func ComparePartitions(DB1Tables, DB2Tables map[string]PartitionedTableData, strictBounds bool) ([]PartitionSummary, []PartitionDifference) {
+	summaries := []PartitionSummary{}
+	differences := []PartitionDifference{}
+
+	for name, DB1Value := range DB1Tables {
+		DB2Value, ok := DB2Tables[name]
+		if !ok {
+			// Missing tables are already reported by the table comparison
+			continue
+		}
+
+		summaries = append(summaries, PartitionSummary{TableName: name, DB1: DB1Value, DB2: DB2Value})
+
+		if DB1Value.Strategy != DB2Value.Strategy {
+			differences = append(differences, PartitionDifference{TableName: name, Attribute: "Strategy", DB1: DB1Value.Strategy, DB2: DB2Value.Strategy, Change: ChangeModified})
+		}
+
+		if DB1Value.Key != DB2Value.Key {
+			differences = append(differences, PartitionDifference{TableName: name, Attribute: "Partition Key", DB1: DB1Value.Key, DB2: DB2Value.Key, Change: ChangeModified})
+		}
+
+		if !strictBounds {
+			continue
+		}
+
+		DB2Bounds := map[string]bool{}
+		for _, bound := range DB2Value.Bounds {
+			DB2Bounds[bound] = true
+		}
+
+		DB1Bounds := map[string]bool{}
+		for _, bound := range DB1Value.Bounds {
+			DB1Bounds[bound] = true
+
+			if !DB2Bounds[bound] {
+				differences = append(differences, PartitionDifference{TableName: name, Attribute: "Partition Bound", DB1: bound, Change: ChangeAdded})
+			}
+		}
+
+		for _, bound := range DB2Value.Bounds {
+			if !DB1Bounds[bound] {
+				differences = append(differences, PartitionDifference{TableName: name, Attribute: "Partition Bound", DB2: bound, Change: ChangeRemoved})
+			}
+		}
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].TableName < summaries[j].TableName
+	})
+
+	sort.SliceStable(differences, func(i, j int) bool {
+		return differences[i].TableName < differences[j].TableName
+	})
+
+	return summaries, differences
+}
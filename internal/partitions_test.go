@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortBounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		bounds []string
+		want   []string
+	}{
+		{
+			name:   "numeric range bounds",
+			bounds: []string{"FOR VALUES FROM (10) TO (20)", "FOR VALUES FROM (9) TO (10)", "FOR VALUES FROM (100) TO (200)"},
+			want:   []string{"FOR VALUES FROM (9) TO (10)", "FOR VALUES FROM (10) TO (20)", "FOR VALUES FROM (100) TO (200)"},
+		},
+		{
+			name:   "date range bounds with default and minvalue",
+			bounds: []string{"DEFAULT", "FOR VALUES FROM ('2024-02-01') TO ('2024-03-01')", "FOR VALUES FROM (MINVALUE) TO ('2024-01-01')", "FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')"},
+			want:   []string{"FOR VALUES FROM (MINVALUE) TO ('2024-01-01')", "FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')", "FOR VALUES FROM ('2024-02-01') TO ('2024-03-01')", "DEFAULT"},
+		},
+		{
+			name:   "multi column range bounds",
+			bounds: []string{"FOR VALUES FROM (2, 10) TO (2, 20)", "FOR VALUES FROM (2, 9) TO (2, 10)", "FOR VALUES FROM (1, 50) TO (2, 9)"},
+			want:   []string{"FOR VALUES FROM (1, 50) TO (2, 9)", "FOR VALUES FROM (2, 9) TO (2, 10)", "FOR VALUES FROM (2, 10) TO (2, 20)"},
+		},
+		{
+			name:   "hash bounds by remainder",
+			bounds: []string{"FOR VALUES WITH (modulus 12, remainder 10)", "FOR VALUES WITH (modulus 12, remainder 2)"},
+			want:   []string{"FOR VALUES WITH (modulus 12, remainder 2)", "FOR VALUES WITH (modulus 12, remainder 10)"},
+		},
+		{
+			name:   "list bounds",
+			bounds: []string{"FOR VALUES IN ('us', 'ca')", "DEFAULT", "FOR VALUES IN ('mx, north')"},
+			want:   []string{"FOR VALUES IN ('mx, north')", "FOR VALUES IN ('us', 'ca')", "DEFAULT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortBounds(tt.bounds)
+
+			if !reflect.DeepEqual(tt.bounds, tt.want) {
+				t.Errorf("sortBounds() = %q, want %q", tt.bounds, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+type RowSecurityData struct {
+	TableName string
+	Enabled   bool
+	Forced    bool
+}
+
+type PolicyData struct {
+	TableName  string
+	PolicyName string
+	Permissive NullString
+	Roles      NullString
+	Command    NullString
+	Using      NullString
+	WithCheck  NullString
+}
+
+// PolicyDifference describes a row level security difference in a table. PolicyName is
+// empty when the difference is in the table's row level security settings.
+type PolicyDifference struct {
+	TableName  string
+	PolicyName string
+	Attribute  string
+	DB1        string
+	DB2        string
+	// Change is added when the policy only exists in the first database and removed when it only exists in the second one
+	Change   ChangeType
+	Severity Severity
+}
+
+func GetDBRowSecurity(db *sql.DB) (map[string]RowSecurityData, error) {
+	rows, err := db.Query(`SELECT
+		c.relname::text, c.relrowsecurity, c.relforcerowsecurity
+	FROM pg_class c
+	INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p')`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := map[string]RowSecurityData{}
+	for rows.Next() {
+		var table RowSecurityData
+
+		err = rows.Scan(&table.TableName, &table.Enabled, &table.Forced)
+		if err != nil {
+			return nil, err
+		}
+
+		tables[table.TableName] = table
+	}
+
+	return tables, rows.Err()
+}
+
+// GetDBPolicies returns the policies of the public schema grouped by table and policy name.
+func GetDBPolicies(db *sql.DB) (map[string]map[string]PolicyData, error) {
+	rows, err := db.Query(`SELECT
+		tablename::text, policyname::text, permissive,
+		(SELECT string_agg(r, ',' ORDER BY r) FROM unnest(roles::text[]) r),
+		cmd, qual, with_check
+	FROM pg_policies
+	WHERE schemaname = 'public'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// table > policy > policyData
+	policies := map[string]map[string]PolicyData{}
+	for rows.Next() {
+		var policy PolicyData
+
+		err = rows.Scan(&policy.TableName, &policy.PolicyName, &policy.Permissive, &policy.Roles, &policy.Command, &policy.Using, &policy.WithCheck)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := policies[policy.TableName]; !ok {
+			policies[policy.TableName] = map[string]PolicyData{}
+		}
+
+		policies[policy.TableName][policy.PolicyName] = policy
+	}
+
+	return policies, rows.Err()
+}
+
+func (p PolicyData) attributes() [][2]string {
+	return [][2]string{
+		{"Permissive", string(p.Permissive)},
+		{"Roles", string(p.Roles)},
+		{"Command", string(p.Command)},
+		{"Using", string(p.Using)},
+		{"With Check", string(p.WithCheck)},
+	}
+}
+
+func CompareTablePolicies(tableName string, DB1Policies, DB2Policies map[string]PolicyData, differences []PolicyDifference) []PolicyDifference {
+	for name, DB1Value := range DB1Policies {
+		DB2Value, ok := DB2Policies[name]
+		if !ok {
+			differences = append(differences, PolicyDifference{TableName: tableName, PolicyName: name, Attribute: "Exists", DB1: "true", Change: ChangeAdded, Severity: SeverityHigh})
+			continue
+		}
+
+		DB2Attributes := DB2Value.attributes()
+		for i, attr := range DB1Value.attributes() {
+			if attr[1] != DB2Attributes[i][1] {
+				differences = append(differences, PolicyDifference{
+					TableName:  tableName,
+					PolicyName: name,
+					Attribute:  attr[0],
+					DB1:        attr[1],
+					DB2:        DB2Attributes[i][1],
+					Change:     ChangeModified,
+					Severity:   SeverityHigh,
+				})
+			}
+		}
+	}
+
+	for name := range DB2Policies {
+		if _, ok := DB1Policies[name]; !ok {
+			differences = append(differences, PolicyDifference{TableName: tableName, PolicyName: name, Attribute: "Exists", DB2: "true", Change: ChangeRemoved, Severity: SeverityHigh})
+		}
+	}
+
+	return differences
+}
+
+// ComparePolicies compares the row level security settings and policies of the tables present in both databases.
+// Every difference is classed as high severity since a missing policy can expose rows.
+func ComparePolicies(DB1 *sql.DB, DB2 *sql.DB) ([]PolicyDifference, error) {
+	differences := []PolicyDifference{}
+
+	DB1RowSecurity, err := GetDBRowSecurity(DB1)
+	if err != nil {
+		return nil, err
+	}
+
+	DB2RowSecurity, err := GetDBRowSecurity(DB2)
+	if err != nil {
+		return nil, err
+	}
+
+	DB1Policies, err := GetDBPolicies(DB1)
+	if err != nil {
+		return nil, err
+	}
+
+	DB2Policies, err := GetDBPolicies(DB2)
+	if err != nil {
+		return nil, err
+	}
+
+	for tableName, DB1Value := range DB1RowSecurity {
+		DB2Value, ok := DB2RowSecurity[tableName]
+		if !ok {
+			// Missing tables are already reported by the table comparison
+			continue
+		}
+
+		if DB1Value.Enabled != DB2Value.Enabled {
+			differences = append(differences, PolicyDifference{
+				TableName: tableName,
+				Attribute: "Row Security Enabled",
+				DB1:       fmt.Sprint(DB1Value.Enabled),
+				DB2:       fmt.Sprint(DB2Value.Enabled),
+				Change:    ChangeModified,
+				Severity:  SeverityHigh,
+			})
+		}
+
+		if DB1Value.Forced != DB2Value.Forced {
+			differences = append(differences, PolicyDifference{
+				TableName: tableName,
+				Attribute: "Row Security Forced",
+				DB1:       fmt.Sprint(DB1Value.Forced),
+				DB2:       fmt.Sprint(DB2Value.Forced),
+				Change:    ChangeModified,
+				Severity:  SeverityHigh,
+			})
+		}
+
+		differences = CompareTablePolicies(tableName, DB1Policies[tableName], DB2Policies[tableName], differences)
+	}
+
+	sort.SliceStable(differences, func(i, j int) bool {
+		if differences[i].TableName != differences[j].TableName {
+			return differences[i].TableName < differences[j].TableName
+		}
+		return differences[i].PolicyName < differences[j].PolicyName
+	})
+
+	return differences, nil
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareTablePolicies(t *testing.T) {
+	ownRows := PolicyData{TableName: "orders", PolicyName: "own_rows", Permissive: "PERMISSIVE", Roles: "app", Command: "SELECT", Using: "(owner = CURRENT_USER)", WithCheck: "Null"}
+	changedRows := ownRows
+	changedRows.Roles = "app,reader"
+	changedRows.Using = "true"
+	allRows := PolicyData{TableName: "orders", PolicyName: "all_rows", Permissive: "RESTRICTIVE", Roles: "admin", Command: "ALL", Using: "true", WithCheck: "true"}
+
+	tests := []struct {
+		name string
+		DB1  map[string]PolicyData
+		DB2  map[string]PolicyData
+		want []PolicyDifference
+	}{
+		{
+			name: "same policies",
+			DB1:  map[string]PolicyData{"own_rows": ownRows},
+			DB2:  map[string]PolicyData{"own_rows": ownRows},
+			want: nil,
+		},
+		{
+			name: "different attributes",
+			DB1:  map[string]PolicyData{"own_rows": ownRows},
+			DB2:  map[string]PolicyData{"own_rows": changedRows},
+			want: []PolicyDifference{
+				{TableName: "orders", PolicyName: "own_rows", Attribute: "Roles", DB1: "app", DB2: "app,reader", Change: ChangeModified, Severity: SeverityHigh},
+				{TableName: "orders", PolicyName: "own_rows", Attribute: "Using", DB1: "(owner = CURRENT_USER)", DB2: "true", Change: ChangeModified, Severity: SeverityHigh},
+			},
+		},
+		{
+			name: "policy only in one database",
+			DB1:  map[string]PolicyData{"own_rows": ownRows},
+			DB2:  map[string]PolicyData{"all_rows": allRows},
+			want: []PolicyDifference{
+				{TableName: "orders", PolicyName: "own_rows", Attribute: "Exists", DB1: "true", Change: ChangeAdded, Severity: SeverityHigh},
+				{TableName: "orders", PolicyName: "all_rows", Attribute: "Exists", DB2: "true", Change: ChangeRemoved, Severity: SeverityHigh},
+			},
+		},
+		{
+			name: "table without policies in the second database",
+			DB1:  map[string]PolicyData{"all_rows": allRows},
+			DB2:  nil,
+			want: []PolicyDifference{
+				{TableName: "orders", PolicyName: "all_rows", Attribute: "Exists", DB1: "true", Change: ChangeAdded, Severity: SeverityHigh},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareTablePolicies("orders", tt.DB1, tt.DB2, nil); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareTablePolicies() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+type PrivilegeData struct {
+	Grantee     string
+	ObjectType  string
+	ObjectName  string
+	Privilege   string
+	IsGrantable string
+}
+
+type OwnerData struct {
+	ObjectType string
+	ObjectName string
+	Owner      string
+}
+
+type RoleData struct {
+	RoleName    string
+	Superuser   bool
+	Inherit     bool
+	CreateRole  bool
+	CreateDB    bool
+	CanLogin    bool
+	Replication bool
+	BypassRLS   bool
+	ConnLimit   int
+}
+
+// PrivilegeDifference describes a privilege that is granted differently to a grantee in each database.
+type PrivilegeDifference struct {
+	Grantee    string
+	ObjectType string
+	ObjectName string
+	Privilege  string
+	DB1        string
+	DB2        string
+	// Change is added when the privilege is only granted in the first database and removed when it is only granted in the second one
+	Change ChangeType
+}
+
+type OwnerDifference struct {
+	ObjectType string
+	ObjectName string
+	DB1        string
+	DB2        string
+	Change     ChangeType
+}
+
+type RoleDifference struct {
+	RoleName  string
+	Attribute string
+	DB1       string
+	DB2       string
+	// Change is added when the role only exists in the first database and removed when it only exists in the second one
+	Change ChangeType
+}
+
+type PrivilegeComparison struct {
+	Privileges []PrivilegeDifference
+	Owners     []OwnerDifference
+	Roles      []RoleDifference
+}
+
+func (p PrivilegeData) key() string {
+	return p.Grantee + "|" + p.ObjectType + "|" + p.ObjectName + "|" + p.Privilege
+}
+
+func (p PrivilegeData) grantStatus() string {
+	if p.IsGrantable == "YES" {
+		return "GRANTED WITH GRANT OPTION"
+	}
+
+	return "GRANTED"
+}
+
+func scanPrivileges(db *sql.DB, query string, privileges map[string]PrivilegeData) error {
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var priv PrivilegeData
+
+		err = rows.Scan(&priv.Grantee, &priv.ObjectType, &priv.ObjectName, &priv.Privilege, &priv.IsGrantable)
+		if err != nil {
+			return err
+		}
+
+		privileges[priv.key()] = priv
+	}
+
+	return rows.Err()
+}
+
+// GetDBPrivileges returns every table, column, schema, sequence and default privilege
+// granted in the public schema, keyed by grantee, object and privilege.
+func GetDBPrivileges(db *sql.DB) (map[string]PrivilegeData, error) {
+	privileges := map[string]PrivilegeData{}
+
+	queries := []string{
+		// Table privileges
+		`SELECT grantee, 'TABLE', table_name, privilege_type, is_grantable
+		FROM information_schema.role_table_grants
+		WHERE table_schema = 'public'`,
+
+		// Column privileges granted on the column itself, information_schema.column_privileges also lists
+		// the ones implied by the table privileges
+		`SELECT
+			CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee) END,
+			'COLUMN', c.relname::text || '.' || att.attname::text, a.privilege_type,
+			CASE WHEN a.is_grantable THEN 'YES' ELSE 'NO' END
+		FROM pg_attribute att
+		INNER JOIN pg_class c ON c.oid = att.attrelid
+		INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+		CROSS JOIN LATERAL aclexplode(att.attacl) a
+		WHERE n.nspname = 'public' AND att.attnum > 0 AND NOT att.attisdropped`,
+
+		// Schema privileges
+		`SELECT
+			CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee) END,
+			'SCHEMA', n.nspname::text, a.privilege_type,
+			CASE WHEN a.is_grantable THEN 'YES' ELSE 'NO' END
+		FROM pg_namespace n
+		CROSS JOIN LATERAL aclexplode(COALESCE(n.nspacl, acldefault('n', n.nspowner))) a
+		WHERE n.nspname = 'public'`,
+
+		// Sequence privileges
+		`SELECT
+			CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee) END,
+			'SEQUENCE', c.relname::text, a.privilege_type,
+			CASE WHEN a.is_grantable THEN 'YES' ELSE 'NO' END
+		FROM pg_class c
+		INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+		CROSS JOIN LATERAL aclexplode(COALESCE(c.relacl, acldefault('s', c.relowner))) a
+		WHERE n.nspname = 'public' AND c.relkind = 'S'`,
+
+		// Default privileges applied to objects created in the future
+		`SELECT
+			CASE WHEN a.grantee = 0 THEN 'PUBLIC' ELSE pg_get_userbyid(a.grantee) END,
+			'DEFAULT',
+			'FOR ROLE ' || pg_get_userbyid(d.defaclrole) ||
+				COALESCE(' IN SCHEMA ' || n.nspname, '') ||
+				CASE d.defaclobjtype
+					WHEN 'r' THEN ' ON TABLES'
+					WHEN 'S' THEN ' ON SEQUENCES'
+					WHEN 'f' THEN ' ON FUNCTIONS'
+					WHEN 'T' THEN ' ON TYPES'
+					WHEN 'n' THEN ' ON SCHEMAS'
+					ELSE ' ON ' || d.defaclobjtype
+				END,
+			a.privilege_type,
+			CASE WHEN a.is_grantable THEN 'YES' ELSE 'NO' END
+		FROM pg_default_acl d
+		LEFT JOIN pg_namespace n ON n.oid = d.defaclnamespace
+		CROSS JOIN LATERAL aclexplode(d.defaclacl) a`,
+	}
+
+	for _, query := range queries {
+		if err := scanPrivileges(db, query, privileges); err != nil {
+			return nil, err
+		}
+	}
+
+	return privileges, nil
+}
+
+// GetDBOwners returns the owner of the public schema and of every relation inside it.
+func GetDBOwners(db *sql.DB) (map[string]OwnerData, error) {
+	rows, err := db.Query(`SELECT 'SCHEMA', n.nspname::text, pg_get_userbyid(n.nspowner)
+	FROM pg_namespace n
+	WHERE n.nspname = 'public'
+	UNION ALL
+	SELECT
+		CASE c.relkind
+			WHEN 'r' THEN 'TABLE'
+			WHEN 'p' THEN 'TABLE'
+			WHEN 'v' THEN 'VIEW'
+			WHEN 'm' THEN 'MATERIALIZED VIEW'
+			WHEN 'S' THEN 'SEQUENCE'
+			WHEN 'f' THEN 'FOREIGN TABLE'
+		END,
+		c.relname::text, pg_get_userbyid(c.relowner)
+	FROM pg_class c
+	INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p', 'v', 'm', 'S', 'f')`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	owners := map[string]OwnerData{}
+	for rows.Next() {
+		var owner OwnerData
+
+		err = rows.Scan(&owner.ObjectType, &owner.ObjectName, &owner.Owner)
+		if err != nil {
+			return nil, err
+		}
+
+		owners[owner.ObjectType+"|"+owner.ObjectName] = owner
+	}
+
+	return owners, rows.Err()
+}
+
+// GetDBRoles returns the attributes of every non-system role in the cluster.
+func GetDBRoles(db *sql.DB) (map[string]RoleData, error) {
+	rows, err := db.Query(`SELECT
+		rolname, rolsuper, rolinherit, rolcreaterole, rolcreatedb, rolcanlogin, rolreplication, rolbypassrls, rolconnlimit
+	FROM pg_roles
+	WHERE rolname NOT LIKE 'pg\_%'
+	ORDER BY rolname ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := map[string]RoleData{}
+	for rows.Next() {
+		var role RoleData
+
+		err = rows.Scan(&role.RoleName, &role.Superuser, &role.Inherit, &role.CreateRole, &role.CreateDB, &role.CanLogin, &role.Replication, &role.BypassRLS, &role.ConnLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		roles[role.RoleName] = role
+	}
+
+	return roles, rows.Err()
+}
+
+func (r RoleData) attributes() [][2]string {
+	return [][2]string{
+		{"Superuser", fmt.Sprint(r.Superuser)},
+		{"Inherit", fmt.Sprint(r.Inherit)},
+		{"Create Role", fmt.Sprint(r.CreateRole)},
+		{"Create DB", fmt.Sprint(r.CreateDB)},
+		{"Can Login", fmt.Sprint(r.CanLogin)},
+		{"Replication", fmt.Sprint(r.Replication)},
+		{"Bypass RLS", fmt.Sprint(r.BypassRLS)},
+		{"Connection Limit", fmt.Sprint(r.ConnLimit)},
+	}
+}
+
+func ComparePrivileges(DB1Privileges, DB2Privileges map[string]PrivilegeData) []PrivilegeDifference {
+	differences := []PrivilegeDifference{}
+
+	for key, DB1Value := range DB1Privileges {
+		DB2Value, ok := DB2Privileges[key]
+		if !ok {
+			differences = append(differences, PrivilegeDifference{
+				Grantee:    DB1Value.Grantee,
+				ObjectType: DB1Value.ObjectType,
+				ObjectName: DB1Value.ObjectName,
+				Privilege:  DB1Value.Privilege,
+				DB1:        DB1Value.grantStatus(),
+				Change:     ChangeAdded,
+			})
+			continue
+		}
+
+		if DB1Value.IsGrantable != DB2Value.IsGrantable {
+			differences = append(differences, PrivilegeDifference{
+				Grantee:    DB1Value.Grantee,
+				ObjectType: DB1Value.ObjectType,
+				ObjectName: DB1Value.ObjectName,
+				Privilege:  DB1Value.Privilege,
+				DB1:        DB1Value.grantStatus(),
+				DB2:        DB2Value.grantStatus(),
+				Change:     ChangeModified,
+			})
+		}
+	}
+
+	for key, DB2Value := range DB2Privileges {
+		if _, ok := DB1Privileges[key]; !ok {
+			differences = append(differences, PrivilegeDifference{
+				Grantee:    DB2Value.Grantee,
+				ObjectType: DB2Value.ObjectType,
+				ObjectName: DB2Value.ObjectName,
+				Privilege:  DB2Value.Privilege,
+				DB2:        DB2Value.grantStatus(),
+				Change:     ChangeRemoved,
+			})
+		}
+	}
+
+	// Group the differences by grantee
+	sort.Slice(differences, func(i, j int) bool {
+		a, b := differences[i], differences[j]
+		if a.Grantee != b.Grantee {
+			return a.Grantee < b.Grantee
+		}
+		if a.ObjectType != b.ObjectType {
+			return a.ObjectType < b.ObjectType
+		}
+		if a.ObjectName != b.ObjectName {
+			return a.ObjectName < b.ObjectName
+		}
+		return a.Privilege < b.Privilege
+	})
+
+	return differences
+}
+
+func CompareOwners(DB1Owners, DB2Owners map[string]OwnerData) []OwnerDifference {
+	differences := []OwnerDifference{}
+
+	for key, DB1Value := range DB1Owners {
+		DB2Value, ok := DB2Owners[key]
+		if !ok {
+			// Missing objects are already reported by the table comparison
+			continue
+		}
+
+		if DB1Value.Owner != DB2Value.Owner {
+			differences = append(differences, OwnerDifference{
+				ObjectType: DB1Value.ObjectType,
+				ObjectName: DB1Value.ObjectName,
+				DB1:        DB1Value.Owner,
+				DB2:        DB2Value.Owner,
+				Change:     ChangeModified,
+			})
+		}
+	}
+
+	sort.Slice(differences, func(i, j int) bool {
+		if differences[i].ObjectType != differences[j].ObjectType {
+			return differences[i].ObjectType < differences[j].ObjectType
+		}
+		return differences[i].ObjectName < differences[j].ObjectName
+	})
+
+	return differences
+}
+
+func CompareRoles(DB1Roles, DB2Roles map[string]RoleData) []RoleDifference {
+	differences := []RoleDifference{}
+
+	for name, DB1Value := range DB1Roles {
+		DB2Value, ok := DB2Roles[name]
+		if !ok {
+			differences = append(differences, RoleDifference{RoleName: name, Attribute: "Exists", DB1: "true", Change: ChangeAdded})
+			continue
+		}
+
+		DB2Attributes := DB2Value.attributes()
+		for i, attr := range DB1Value.attributes() {
+			if attr[1] != DB2Attributes[i][1] {
+				differences = append(differences, RoleDifference{
+					RoleName:  name,
+					Attribute: attr[0],
+					DB1:       attr[1],
+					DB2:       DB2Attributes[i][1],
+					Change:    ChangeModified,
+				})
+			}
+		}
+	}
+
+	for name := range DB2Roles {
+		if _, ok := DB1Roles[name]; !ok {
+			differences = append(differences, RoleDifference{RoleName: name, Attribute: "Exists", DB2: "true", Change: ChangeRemoved})
+		}
+	}
+
+	sort.SliceStable(differences, func(i, j int) bool {
+		return differences[i].RoleName < differences[j].RoleName
+	})
+
+	return differences
+}
+
+// ComparePrivilegeData compares grants, object ownership and role attributes between both databases.
+func ComparePrivilegeData(DB1 *sql.DB, DB2 *sql.DB) (PrivilegeComparison, error) {
+	comparison := PrivilegeComparison{}
+
+	DB1Privileges, err := GetDBPrivileges(DB1)
+	if err != nil {
+		return comparison, err
+	}
+
+	DB2Privileges, err := GetDBPrivileges(DB2)
+	if err != nil {
+		return comparison, err
+	}
+
+	DB1Owners, err := GetDBOwners(DB1)
+	if err != nil {
+		return comparison, err
+	}
+
+	DB2Owners, err := GetDBOwners(DB2)
+	if err != nil {
+		return comparison, err
+	}
+
+	DB1Roles, err := GetDBRoles(DB1)
+	if err != nil {
+		return comparison, err
+	}
+
+	DB2Roles, err := GetDBRoles(DB2)
+	if err != nil {
+		return comparison, err
+	}
+
+	comparison.Privileges = ComparePrivileges(DB1Privileges, DB2Privileges)
+	comparison.Owners = CompareOwners(DB1Owners, DB2Owners)
+	comparison.Roles = CompareRoles(DB1Roles, DB2Roles)
+
+	return comparison, nil
+}
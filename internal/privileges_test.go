@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComparePrivileges(t *testing.T) {
+	privileges := func(values ...PrivilegeData) map[string]PrivilegeData {
+		m := map[string]PrivilegeData{}
+		for _, p := range values {
+			m[p.key()] = p
+		}
+		return m
+	}
+	selectOrders := PrivilegeData{Grantee: "reader", ObjectType: "TABLE", ObjectName: "orders", Privilege: "SELECT", IsGrantable: "NO"}
+	grantableSelectOrders := PrivilegeData{Grantee: "reader", ObjectType: "TABLE", ObjectName: "orders", Privilege: "SELECT", IsGrantable: "YES"}
+	updateColumn := PrivilegeData{Grantee: "writer", ObjectType: "COLUMN", ObjectName: "orders.total", Privilege: "UPDATE", IsGrantable: "NO"}
+
+	tests := []struct {
+		name string
+		DB1  map[string]PrivilegeData
+		DB2  map[string]PrivilegeData
+		want []PrivilegeDifference
+	}{
+		{
+			name: "same privileges",
+			DB1:  privileges(selectOrders, updateColumn),
+			DB2:  privileges(selectOrders, updateColumn),
+			want: []PrivilegeDifference{},
+		},
+		{
+			name: "only granted in the first database",
+			DB1:  privileges(selectOrders),
+			DB2:  privileges(),
+			want: []PrivilegeDifference{{Grantee: "reader", ObjectType: "TABLE", ObjectName: "orders", Privilege: "SELECT", DB1: "GRANTED", Change: ChangeAdded}},
+		},
+		{
+			name: "only granted in the second database",
+			DB1:  privileges(),
+			DB2:  privileges(updateColumn),
+			want: []PrivilegeDifference{{Grantee: "writer", ObjectType: "COLUMN", ObjectName: "orders.total", Privilege: "UPDATE", DB2: "GRANTED", Change: ChangeRemoved}},
+		},
+		{
+			name: "different grant option",
+			DB1:  privileges(grantableSelectOrders),
+			DB2:  privileges(selectOrders),
+			want: []PrivilegeDifference{{Grantee: "reader", ObjectType: "TABLE", ObjectName: "orders", Privilege: "SELECT", DB1: "GRANTED WITH GRANT OPTION", DB2: "GRANTED", Change: ChangeModified}},
+		},
+		{
+			name: "grouped by grantee",
+			DB1:  privileges(updateColumn),
+			DB2:  privileges(selectOrders),
+			want: []PrivilegeDifference{
+				{Grantee: "reader", ObjectType: "TABLE", ObjectName: "orders", Privilege: "SELECT", DB2: "GRANTED", Change: ChangeRemoved},
+				{Grantee: "writer", ObjectType: "COLUMN", ObjectName: "orders.total", Privilege: "UPDATE", DB1: "GRANTED", Change: ChangeAdded},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComparePrivileges(tt.DB1, tt.DB2); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ComparePrivileges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareOwners(t *testing.T) {
+	owners := func(values ...OwnerData) map[string]OwnerData {
+		m := map[string]OwnerData{}
+		for _, o := range values {
+			m[o.ObjectType+"|"+o.ObjectName] = o
+		}
+		return m
+	}
+
+	tests := []struct {
+		name string
+		DB1  map[string]OwnerData
+		DB2  map[string]OwnerData
+		want []OwnerDifference
+	}{
+		{
+			name: "same owner",
+			DB1:  owners(OwnerData{ObjectType: "TABLE", ObjectName: "orders", Owner: "app"}),
+			DB2:  owners(OwnerData{ObjectType: "TABLE", ObjectName: "orders", Owner: "app"}),
+			want: []OwnerDifference{},
+		},
+		{
+			name: "different owner",
+			DB1:  owners(OwnerData{ObjectType: "TABLE", ObjectName: "orders", Owner: "app"}),
+			DB2:  owners(OwnerData{ObjectType: "TABLE", ObjectName: "orders", Owner: "postgres"}),
+			want: []OwnerDifference{{ObjectType: "TABLE", ObjectName: "orders", DB1: "app", DB2: "postgres", Change: ChangeModified}},
+		},
+		{
+			name: "missing objects are ignored",
+			DB1:  owners(OwnerData{ObjectType: "TABLE", ObjectName: "orders", Owner: "app"}),
+			DB2:  owners(OwnerData{ObjectType: "VIEW", ObjectName: "totals", Owner: "app"}),
+			want: []OwnerDifference{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareOwners(tt.DB1, tt.DB2); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareOwners() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareRoles(t *testing.T) {
+	tests := []struct {
+		name string
+		DB1  map[string]RoleData
+		DB2  map[string]RoleData
+		want []RoleDifference
+	}{
+		{
+			name: "same attributes",
+			DB1:  map[string]RoleData{"app": {RoleName: "app", CanLogin: true, ConnLimit: -1}},
+			DB2:  map[string]RoleData{"app": {RoleName: "app", CanLogin: true, ConnLimit: -1}},
+			want: []RoleDifference{},
+		},
+		{
+			name: "different attributes",
+			DB1:  map[string]RoleData{"app": {RoleName: "app", CanLogin: true, ConnLimit: -1}},
+			DB2:  map[string]RoleData{"app": {RoleName: "app", CanLogin: false, ConnLimit: 10}},
+			want: []RoleDifference{
+				{RoleName: "app", Attribute: "Can Login", DB1: "true", DB2: "false", Change: ChangeModified},
+				{RoleName: "app", Attribute: "Connection Limit", DB1: "-1", DB2: "10", Change: ChangeModified},
+			},
+		},
+		{
+			name: "role only in one database",
+			DB1:  map[string]RoleData{"reader": {RoleName: "reader"}},
+			DB2:  map[string]RoleData{"app": {RoleName: "app"}},
+			want: []RoleDifference{
+				{RoleName: "app", Attribute: "Exists", DB2: "true", Change: ChangeRemoved},
+				{RoleName: "reader", Attribute: "Exists", DB1: "true", Change: ChangeAdded},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareRoles(tt.DB1, tt.DB2); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareRoles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
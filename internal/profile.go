@@ -0,0 +1,317 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// orderedTypes are the data types min and max can be computed for.
+var orderedTypes = map[string]bool{
+	"smallint":                    true,
+	"integer":                     true,
+	"bigint":                      true,
+	"numeric":                     true,
+	"real":                        true,
+	"double precision":            true,
+	"money":                       true,
+	"date":                        true,
+	"interval":                    true,
+	"time without time zone":      true,
+	"time with time zone":         true,
+	"timestamp without time zone": true,
+	"timestamp with time zone":    true,
+	"character":                   true,
+	"character varying":           true,
+	"text":                        true,
+}
+
+// numericTypes are the data types whose min and max are compared within the tolerance.
+var numericTypes = map[string]bool{
+	"smallint":         true,
+	"integer":          true,
+	"bigint":           true,
+	"numeric":          true,
+	"real":             true,
+	"double precision": true,
+}
+
+type ValueCount struct {
+	Value string
+	Count int64
+}
+
+type ColumnProfile struct {
+	Rows      int64
+	NullRatio float64
+	Distinct  int64
+	Min       NullString
+	Max       NullString
+	AvgLength float64
+	TopValues []ValueCount
+}
+
+type ProfileDifference struct {
+	TableName  string
+	ColumnName string
+	Metric     string
+	DB1        string
+	DB2        string
+	Change     ChangeType
+}
+
+type ProfileOptions struct {
+	// Tolerance is the allowed difference in percentage points for the null ratio and the share of
+	// the most frequent values, and in percent for the distinct count, average length and numeric min/max
+	Tolerance float64
+	TopN      int
+}
+
+// profileQuery returns the query computing the profile of the columns, one row with the statistics
+// of each column and one row per most frequent value.
+func profileQuery(tableName string, columns []ColumnData, topN int) string {
+	keys, sets, selects := []string{}, []string{}, []string{}
+	for i, col := range columns {
+		key := pq.QuoteIdentifier(col.ColumnName)
+		minMax := "NULL, NULL"
+		if orderedTypes[string(col.DataType)] {
+			minMax = fmt.Sprintf("min(k%[1]d)::text, max(k%[1]d)::text", i)
+		} else {
+			// Grouped as text so types without an equality operator, such as json, can be profiled
+			key += "::text"
+		}
+
+		keys = append(keys, fmt.Sprintf("GROUPING(%[1]s) AS g%[2]d, %[1]s AS k%[2]d", key, i))
+		sets = append(sets, "("+key+")")
+
+		selects = append(selects, fmt.Sprintf(`SELECT
+		%[1]d, sum(n)::bigint, sum(n) FILTER (WHERE k%[1]d IS NOT NULL)::bigint, count(k%[1]d), %[2]s,
+		sum(length(k%[1]d::text) * n)::float8 / sum(n) FILTER (WHERE k%[1]d IS NOT NULL), NULL::text, NULL::bigint
+	FROM groups
+	WHERE g%[1]d = 0`, i, minMax))
+
+		if topN > 0 {
+			selects = append(selects, fmt.Sprintf(`(SELECT
+		%[1]d, NULL, NULL, NULL, NULL, NULL, NULL, k%[1]d::text, n
+	FROM groups
+	WHERE g%[1]d = 0 AND k%[1]d IS NOT NULL
+	ORDER BY n DESC, k%[1]d::text ASC
+	LIMIT %[2]d)`, i, topN))
+		}
+	}
+
+	return fmt.Sprintf(`WITH groups AS (
+		SELECT %s, count(*) AS n
+		FROM %s
+		GROUP BY GROUPING SETS (%s)
+	)
+	%s`, strings.Join(keys, ", "), qualifiedTable(tableName), strings.Join(sets, ", "), strings.Join(selects, "\n\tUNION ALL\n\t"))
+}
+
+// GetTableProfile computes the statistics of the given columns of a table in a single scan. The rows
+// are grouped by each column with grouping sets and the statistics of every column are computed from
+// its groups.
+func GetTableProfile(db *sql.DB, tableName string, columns []ColumnData, topN int) (map[string]ColumnProfile, error) {
+	profiles := map[string]ColumnProfile{}
+	if len(columns) == 0 {
+		return profiles, nil
+	}
+
+	rows, err := db.Query(profileQuery(tableName, columns, topN))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var i int
+		var total, nonNull, distinct, count sql.NullInt64
+		var min, max NullString
+		var avgLength sql.NullFloat64
+		var value sql.NullString
+
+		err = rows.Scan(&i, &total, &nonNull, &distinct, &min, &max, &avgLength, &value, &count)
+		if err != nil {
+			return nil, err
+		}
+
+		name := columns[i].ColumnName
+		profile := profiles[name]
+		if value.Valid {
+			profile.TopValues = append(profile.TopValues, ValueCount{Value: value.String, Count: count.Int64})
+		} else {
+			profile.Rows, profile.Distinct, profile.Min, profile.Max = total.Int64, distinct.Int64, min, max
+			if profile.Rows > 0 {
+				profile.NullRatio = float64(profile.Rows-nonNull.Int64) / float64(profile.Rows) * 100
+			}
+			profile.AvgLength = avgLength.Float64
+		}
+		profiles[name] = profile
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// The branches of UNION ALL are not guaranteed to keep their order
+	for name, profile := range profiles {
+		sort.SliceStable(profile.TopValues, func(i, j int) bool {
+			return profile.TopValues[i].Count > profile.TopValues[j].Count
+		})
+		profiles[name] = profile
+	}
+
+	return profiles, nil
+}
+
+// share returns the percentage of the rows holding the value.
+func (p ColumnProfile) share(v ValueCount) float64 {
+	if p.Rows == 0 {
+		return 0
+	}
+
+	return float64(v.Count) / float64(p.Rows) * 100
+}
+
+func (p ColumnProfile) topValues() string {
+	values := []string{}
+	for _, v := range p.TopValues {
+		values = append(values, fmt.Sprintf("%s (%.2f%%)", v.Value, p.share(v)))
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// topValuesDrift reports whether the most frequent values differ between both profiles, or their share
+// of the rows differs by more than the tolerance in percentage points.
+func topValuesDrift(DB1Profile, DB2Profile ColumnProfile, tolerance float64) bool {
+	if len(DB1Profile.TopValues) != len(DB2Profile.TopValues) {
+		return true
+	}
+
+	DB2Shares := map[string]float64{}
+	for _, v := range DB2Profile.TopValues {
+		DB2Shares[v.Value] = DB2Profile.share(v)
+	}
+
+	for _, v := range DB1Profile.TopValues {
+		DB2Share, ok := DB2Shares[v.Value]
+		if !ok || math.Abs(DB1Profile.share(v)-DB2Share) > tolerance {
+			return true
+		}
+	}
+
+	return false
+}
+
+// valueDrift reports whether a min or max value differs between both databases. Numbers are compared
+// within the tolerance, in percent, other values must be equal.
+func valueDrift(col ColumnData, DB1Value, DB2Value NullString, tolerance float64) bool {
+	if numericTypes[string(col.DataType)] {
+		a, errA := strconv.ParseFloat(string(DB1Value), 64)
+		b, errB := strconv.ParseFloat(string(DB2Value), 64)
+		if errA == nil && errB == nil {
+			return relativeDiff(a, b) > tolerance
+		}
+	}
+
+	return DB1Value != DB2Value
+}
+
+func relativeDiff(a, b float64) float64 {
+	if a == b {
+		return 0
+	}
+
+	return math.Abs(a-b) / math.Max(math.Abs(a), math.Abs(b)) * 100
+}
+
+// CompareColumnProfiles compares the profile of a column in both databases and returns the
+// metrics that differ by more than the tolerance.
+func CompareColumnProfiles(col ColumnData, DB1Profile, DB2Profile ColumnProfile, options ProfileOptions) []ProfileDifference {
+	differences := []ProfileDifference{}
+
+	add := func(metric, DB1Value, DB2Value string) {
+		differences = append(differences, ProfileDifference{
+			TableName:  col.TableName,
+			ColumnName: col.ColumnName,
+			Metric:     metric,
+			DB1:        DB1Value,
+			DB2:        DB2Value,
+			Change:     ChangeModified,
+		})
+	}
+
+	if math.Abs(DB1Profile.NullRatio-DB2Profile.NullRatio) > options.Tolerance {
+		add("Null Ratio %", fmt.Sprintf("%.2f", DB1Profile.NullRatio), fmt.Sprintf("%.2f", DB2Profile.NullRatio))
+	}
+
+	if relativeDiff(float64(DB1Profile.Distinct), float64(DB2Profile.Distinct)) > options.Tolerance {
+		add("Distinct Count", fmt.Sprint(DB1Profile.Distinct), fmt.Sprint(DB2Profile.Distinct))
+	}
+
+	if relativeDiff(DB1Profile.AvgLength, DB2Profile.AvgLength) > options.Tolerance {
+		add("Average Length", fmt.Sprintf("%.2f", DB1Profile.AvgLength), fmt.Sprintf("%.2f", DB2Profile.AvgLength))
+	}
+
+	if valueDrift(col, DB1Profile.Min, DB2Profile.Min, options.Tolerance) {
+		add("Min", string(DB1Profile.Min), string(DB2Profile.Min))
+	}
+
+	if valueDrift(col, DB1Profile.Max, DB2Profile.Max, options.Tolerance) {
+		add("Max", string(DB1Profile.Max), string(DB2Profile.Max))
+	}
+
+	if topValuesDrift(DB1Profile, DB2Profile, options.Tolerance) {
+		add(fmt.Sprintf("Top %d Values", options.TopN), DB1Profile.topValues(), DB2Profile.topValues())
+	}
+
+	return differences
+}
+
+// CompareProfiles profiles every column with the same data type in the given tables of both
+// databases and returns the statistical drift found.
+func CompareProfiles(DB1 *sql.DB, DB2 *sql.DB, DB1Tables, DB2Tables map[string]map[string]ColumnData, tables []string, options ProfileOptions) ([]ProfileDifference, error) {
+	differences := []ProfileDifference{}
+
+	sort.Strings(tables)
+	for _, table := range tables {
+		columns := []string{}
+		for name := range DB1Tables[table] {
+			columns = append(columns, name)
+		}
+		sort.Strings(columns)
+
+		DB1Cols, DB2Cols := []ColumnData{}, []ColumnData{}
+		for _, name := range columns {
+			DB1Col := DB1Tables[table][name]
+			DB2Col, ok := DB2Tables[table][name]
+			if !ok || DB1Col.DataType != DB2Col.DataType {
+				// Schema differences are already reported by the column comparison
+				continue
+			}
+
+			DB1Cols, DB2Cols = append(DB1Cols, DB1Col), append(DB2Cols, DB2Col)
+		}
+
+		DB1Profiles, err := GetTableProfile(DB1, table, DB1Cols, options.TopN)
+		if err != nil {
+			return nil, err
+		}
+
+		DB2Profiles, err := GetTableProfile(DB2, table, DB2Cols, options.TopN)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, col := range DB1Cols {
+			differences = append(differences, CompareColumnProfiles(col, DB1Profiles[col.ColumnName], DB2Profiles[col.ColumnName], options)...)
+		}
+	}
+
+	return differences, nil
+}
@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValueDrift(t *testing.T) {
+	integer := ColumnData{DataType: "integer"}
+	text := ColumnData{DataType: "text"}
+
+	tests := []struct {
+		name      string
+		col       ColumnData
+		DB1, DB2  NullString
+		tolerance float64
+		want      bool
+	}{
+		{name: "equal numbers", col: integer, DB1: "100", DB2: "100", want: false},
+		{name: "numbers within tolerance", col: integer, DB1: "100", DB2: "104", tolerance: 5, want: false},
+		{name: "numbers outside tolerance", col: integer, DB1: "100", DB2: "110", tolerance: 5, want: true},
+		{name: "negative numbers", col: integer, DB1: "-100", DB2: "-98", tolerance: 5, want: false},
+		{name: "null and number", col: integer, DB1: "Null", DB2: "0", tolerance: 5, want: true},
+		{name: "both null", col: integer, DB1: "Null", DB2: "Null", want: false},
+		{name: "text ignores tolerance", col: text, DB1: "100", DB2: "104", tolerance: 5, want: true},
+		{name: "equal text", col: text, DB1: "apple", DB2: "apple", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := valueDrift(tt.col, tt.DB1, tt.DB2, tt.tolerance); got != tt.want {
+				t.Errorf("valueDrift(%q, %q) = %v, want %v", tt.DB1, tt.DB2, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopValuesDrift(t *testing.T) {
+	profile := func(rows int64, values ...ValueCount) ColumnProfile {
+		return ColumnProfile{Rows: rows, TopValues: values}
+	}
+
+	tests := []struct {
+		name      string
+		DB1, DB2  ColumnProfile
+		tolerance float64
+		want      bool
+	}{
+		{
+			name: "same values and shares",
+			DB1:  profile(100, ValueCount{"active", 60}, ValueCount{"closed", 40}),
+			DB2:  profile(200, ValueCount{"active", 120}, ValueCount{"closed", 80}),
+			want: false,
+		},
+		{
+			name: "same values in another order",
+			DB1:  profile(100, ValueCount{"active", 50}, ValueCount{"closed", 50}),
+			DB2:  profile(100, ValueCount{"closed", 50}, ValueCount{"active", 50}),
+			want: false,
+		},
+		{
+			name:      "shares within tolerance",
+			DB1:       profile(100, ValueCount{"active", 60}),
+			DB2:       profile(100, ValueCount{"active", 58}),
+			tolerance: 5,
+			want:      false,
+		},
+		{
+			name:      "shares outside tolerance",
+			DB1:       profile(100, ValueCount{"active", 60}),
+			DB2:       profile(100, ValueCount{"active", 40}),
+			tolerance: 5,
+			want:      true,
+		},
+		{
+			name: "different values",
+			DB1:  profile(100, ValueCount{"active", 60}),
+			DB2:  profile(100, ValueCount{"closed", 60}),
+			want: true,
+		},
+		{
+			name: "different number of values",
+			DB1:  profile(100, ValueCount{"active", 60}, ValueCount{"closed", 40}),
+			DB2:  profile(100, ValueCount{"active", 60}),
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := topValuesDrift(tt.DB1, tt.DB2, tt.tolerance); got != tt.want {
+				t.Errorf("topValuesDrift() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareColumnProfiles(t *testing.T) {
+	col := ColumnData{TableName: "orders", ColumnName: "total", DataType: "numeric"}
+	base := ColumnProfile{Rows: 100, NullRatio: 10, Distinct: 80, Min: "1", Max: "500", AvgLength: 3, TopValues: []ValueCount{{"10", 5}}}
+
+	tests := []struct {
+		name   string
+		change func(*ColumnProfile)
+		want   []string
+	}{
+		{name: "same profile", change: func(p *ColumnProfile) {}, want: []string{}},
+		{name: "within tolerance", change: func(p *ColumnProfile) { p.NullRatio, p.Distinct, p.Max = 12, 82, "510" }, want: []string{}},
+		{name: "null ratio", change: func(p *ColumnProfile) { p.NullRatio = 40 }, want: []string{"Null Ratio %"}},
+		{name: "distinct count", change: func(p *ColumnProfile) { p.Distinct = 20 }, want: []string{"Distinct Count"}},
+		{name: "min and max", change: func(p *ColumnProfile) { p.Min, p.Max = "-50", "5000" }, want: []string{"Min", "Max"}},
+		{name: "top values", change: func(p *ColumnProfile) { p.TopValues = []ValueCount{{"20", 5}} }, want: []string{"Top 1 Values"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			DB2Profile := base
+			tt.change(&DB2Profile)
+
+			got := []string{}
+			for _, diff := range CompareColumnProfiles(col, base, DB2Profile, ProfileOptions{Tolerance: 5, TopN: 1}) {
+				got = append(got, diff.Metric)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareColumnProfiles() metrics = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
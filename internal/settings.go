@@ -0,0 +1,239 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultIgnoredSettings are host specific settings that are expected to differ between servers.
+var DefaultIgnoredSettings = []string{
+	"application_name",
+	"cluster_name",
+	"config_file",
+	"data_directory",
+	"external_pid_file",
+	"hba_file",
+	"ident_file",
+	"in_hot_standby",
+	"krb_server_keyfile",
+	"listen_addresses",
+	"log_directory",
+	"port",
+	"server_version",
+	"server_version_num",
+	"ssl_ca_file",
+	"ssl_cert_file",
+	"ssl_crl_file",
+	"ssl_key_file",
+	"stats_temp_directory",
+	"transaction_read_only",
+	"unix_socket_directories",
+}
+
+type SettingData struct {
+	Name     string
+	Setting  NullString
+	Unit     NullString
+	Category string
+}
+
+type ServerInfo struct {
+	Version  string
+	Encoding string
+	Collate  string
+	Ctype    string
+}
+
+type SettingDifference struct {
+	Name     string
+	Category string
+	DB1      string
+	DB2      string
+	// Change is added when the setting only exists in the first database and removed when it only exists in the second one
+	Change ChangeType
+}
+
+type SettingsComparison struct {
+	DB1Server   ServerInfo
+	DB2Server   ServerInfo
+	Differences []SettingDifference
+}
+
+var memoryUnits = map[string]int64{
+	"B":    1,
+	"kB":   1024,
+	"8kB":  8 * 1024,
+	"16kB": 16 * 1024,
+	"32kB": 32 * 1024,
+	"64kB": 64 * 1024,
+	"MB":   1024 * 1024,
+	"16MB": 16 * 1024 * 1024,
+	"GB":   1024 * 1024 * 1024,
+	"TB":   1024 * 1024 * 1024 * 1024,
+}
+
+var timeUnits = map[string]int64{
+	"us":  1,
+	"ms":  1000,
+	"s":   1000 * 1000,
+	"min": 60 * 1000 * 1000,
+	"h":   60 * 60 * 1000 * 1000,
+	"d":   24 * 60 * 60 * 1000 * 1000,
+}
+
+// formatUnits formats value with the largest unit that represents it exactly.
+func formatUnits(value int64, units []string, sizes map[string]int64) string {
+	for i := len(units) - 1; i > 0; i-- {
+		size := sizes[units[i]]
+		if value != 0 && value%size == 0 {
+			return fmt.Sprintf("%d%s", value/size, units[i])
+		}
+	}
+
+	return fmt.Sprintf("%d%s", value, units[0])
+}
+
+// NormalizeSetting converts a setting to its base unit so values reported with different
+// units (e.g. 8kB pages and MB) can be compared, and formats it in the largest exact unit.
+func NormalizeSetting(setting, unit string) string {
+	value, err := strconv.ParseInt(setting, 10, 64)
+	if err != nil || unit == "" {
+		return setting
+	}
+
+	// Negative values such as -1 are used to disable a setting
+	if value < 0 {
+		return setting
+	}
+
+	if size, ok := memoryUnits[unit]; ok {
+		return formatUnits(value*size, []string{"B", "kB", "MB", "GB", "TB"}, memoryUnits)
+	}
+
+	if size, ok := timeUnits[unit]; ok {
+		return formatUnits(value*size, []string{"us", "ms", "s", "min", "h", "d"}, timeUnits)
+	}
+
+	return setting + unit
+}
+
+func GetDBSettings(db *sql.DB) (map[string]SettingData, error) {
+	rows, err := db.Query(`SELECT name, setting, unit, category FROM pg_settings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := map[string]SettingData{}
+	for rows.Next() {
+		var setting SettingData
+
+		err = rows.Scan(&setting.Name, &setting.Setting, &setting.Unit, &setting.Category)
+		if err != nil {
+			return nil, err
+		}
+
+		settings[setting.Name] = setting
+	}
+
+	return settings, rows.Err()
+}
+
+func GetServerInfo(db *sql.DB) (ServerInfo, error) {
+	var info ServerInfo
+
+	err := db.QueryRow(`SELECT
+		current_setting('server_version'), pg_encoding_to_char(d.encoding), d.datcollate::text, d.datctype::text
+	FROM pg_database d
+	WHERE d.datname = current_database()`).Scan(&info.Version, &info.Encoding, &info.Collate, &info.Ctype)
+
+	return info, err
+}
+
+func (s SettingData) normalized() string {
+	if s.Unit == "Null" {
+		return string(s.Setting)
+	}
+
+	return NormalizeSetting(string(s.Setting), string(s.Unit))
+}
+
+// CompareSettings compares the server configuration of both databases, skipping the ignored settings.
+func CompareSettings(DB1 *sql.DB, DB2 *sql.DB, ignored []string) (SettingsComparison, error) {
+	comparison := SettingsComparison{Differences: []SettingDifference{}}
+
+	ignoredSettings := map[string]bool{}
+	for _, name := range ignored {
+		ignoredSettings[strings.ToLower(name)] = true
+	}
+
+	var err error
+	comparison.DB1Server, err = GetServerInfo(DB1)
+	if err != nil {
+		return comparison, err
+	}
+
+	comparison.DB2Server, err = GetServerInfo(DB2)
+	if err != nil {
+		return comparison, err
+	}
+
+	serverAttributes := [][3]string{
+		{"Server Version", comparison.DB1Server.Version, comparison.DB2Server.Version},
+		{"Encoding", comparison.DB1Server.Encoding, comparison.DB2Server.Encoding},
+		{"Collate", comparison.DB1Server.Collate, comparison.DB2Server.Collate},
+		{"Ctype", comparison.DB1Server.Ctype, comparison.DB2Server.Ctype},
+	}
+	for _, attr := range serverAttributes {
+		if attr[1] != attr[2] {
+			comparison.Differences = append(comparison.Differences, SettingDifference{Name: attr[0], Category: "Server", DB1: attr[1], DB2: attr[2], Change: ChangeModified})
+		}
+	}
+
+	DB1Settings, err := GetDBSettings(DB1)
+	if err != nil {
+		return comparison, err
+	}
+
+	DB2Settings, err := GetDBSettings(DB2)
+	if err != nil {
+		return comparison, err
+	}
+
+	settingDifferences := []SettingDifference{}
+	for name, DB1Value := range DB1Settings {
+		if ignoredSettings[name] {
+			continue
+		}
+
+		DB2Value, ok := DB2Settings[name]
+		if !ok {
+			settingDifferences = append(settingDifferences, SettingDifference{Name: name, Category: DB1Value.Category, DB1: DB1Value.normalized(), Change: ChangeAdded})
+			continue
+		}
+
+		if DB1Value.normalized() != DB2Value.normalized() {
+			settingDifferences = append(settingDifferences, SettingDifference{Name: name, Category: DB1Value.Category, DB1: DB1Value.normalized(), DB2: DB2Value.normalized(), Change: ChangeModified})
+		}
+	}
+
+	for name, DB2Value := range DB2Settings {
+		if ignoredSettings[name] {
+			continue
+		}
+
+		if _, ok := DB1Settings[name]; !ok {
+			settingDifferences = append(settingDifferences, SettingDifference{Name: name, Category: DB2Value.Category, DB2: DB2Value.normalized(), Change: ChangeRemoved})
+		}
+	}
+
+	sort.Slice(settingDifferences, func(i, j int) bool {
+		return settingDifferences[i].Name < settingDifferences[j].Name
+	})
+	comparison.Differences = append(comparison.Differences, settingDifferences...)
+
+	return comparison, nil
+}
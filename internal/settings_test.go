@@ -0,0 +1,52 @@
+package internal
+
+import "testing"
+
+func TestNormalizeSetting(t *testing.T) {
+	tests := []struct {
+		setting string
+		unit    string
+		want    string
+	}{
+		{setting: "1024", unit: "8kB", want: "8MB"},
+		{setting: "16384", unit: "kB", want: "16MB"},
+		{setting: "3", unit: "kB", want: "3kB"},
+		{setting: "128", unit: "MB", want: "128MB"},
+		{setting: "1000", unit: "ms", want: "1s"},
+		{setting: "60", unit: "s", want: "1min"},
+		{setting: "90", unit: "s", want: "90s"},
+		{setting: "0", unit: "ms", want: "0us"},
+		{setting: "-1", unit: "ms", want: "-1"},
+		{setting: "100", unit: "", want: "100"},
+		{setting: "on", unit: "", want: "on"},
+		{setting: "100", unit: "percent", want: "100percent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.setting+tt.unit, func(t *testing.T) {
+			if got := NormalizeSetting(tt.setting, tt.unit); got != tt.want {
+				t.Errorf("NormalizeSetting(%q, %q) = %q, want %q", tt.setting, tt.unit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettingNormalized(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting SettingData
+		want    string
+	}{
+		{name: "without unit", setting: SettingData{Name: "timezone", Setting: "UTC", Unit: "Null"}, want: "UTC"},
+		{name: "pages", setting: SettingData{Name: "shared_buffers", Setting: "16384", Unit: "8kB"}, want: "128MB"},
+		{name: "same value in other units", setting: SettingData{Name: "shared_buffers", Setting: "131072", Unit: "kB"}, want: "128MB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.setting.normalized(); got != tt.want {
+				t.Errorf("normalized() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
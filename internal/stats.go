@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"database/sql"
+	"math"
+	"sort"
+
+	"github.com/lib/pq"
+)
+
+type TableStats struct {
+	EstimatedRows int64
+	// ExactRows is -1 when the exact row count was not requested
+	ExactRows int64
+	TotalSize int64
+}
+
+// TableStatsComparison holds the statistics of a table present in both databases and
+// the percentage difference between them.
+type TableStatsComparison struct {
+	TableName       string
+	DB1             TableStats
+	DB2             TableStats
+	RowDiffPercent  float64
+	SizeDiffPercent float64
+	Flagged         bool
+}
+
+// Rows returns the exact row count when available, otherwise the planner estimate.
+func (s TableStats) Rows() int64 {
+	if s.ExactRows >= 0 {
+		return s.ExactRows
+	}
+
+	return s.EstimatedRows
+}
+
+func GetTableStats(db *sql.DB, tableName string, exactCount bool) (TableStats, error) {
+	stats := TableStats{ExactRows: -1}
+
+	// pg_partition_tree returns the table itself for regular tables and the leaf partitions
+	// for partitioned tables, which have no storage of their own
+	var estimatedRows float64
+	err := db.QueryRow(`SELECT
+		COALESCE(SUM(GREATEST(c.reltuples, 0)), 0), COALESCE(SUM(pg_total_relation_size(c.oid)), 0)
+	FROM pg_partition_tree((quote_ident('public') || '.' || quote_ident($1))::regclass) p
+	INNER JOIN pg_class c ON c.oid = p.relid
+	WHERE p.isleaf`, tableName).Scan(&estimatedRows, &stats.TotalSize)
+	if err != nil {
+		return stats, err
+	}
+	stats.EstimatedRows = int64(estimatedRows)
+
+	if exactCount {
+		err = db.QueryRow("SELECT count(*) FROM public." + pq.QuoteIdentifier(tableName)).Scan(&stats.ExactRows)
+		if err != nil {
+			return stats, err
+		}
+	}
+
+	return stats, nil
+}
+
+func diffPercent(a, b int64) float64 {
+	if a == b {
+		return 0
+	}
+
+	return math.Abs(float64(a-b)) / float64(max(a, b)) * 100
+}
+
+// CompareTableStats compares the row count and size of the given tables. Tables whose row
+// count or size differ by more than threshold percent are flagged.
+func CompareTableStats(DB1 *sql.DB, DB2 *sql.DB, tables []string, exactCount bool, threshold float64) ([]TableStatsComparison, error) {
+	comparisons := []TableStatsComparison{}
+
+	sort.Strings(tables)
+	for _, table := range tables {
+		DB1Stats, err := GetTableStats(DB1, table, exactCount)
+		if err != nil {
+			return nil, err
+		}
+
+		DB2Stats, err := GetTableStats(DB2, table, exactCount)
+		if err != nil {
+			return nil, err
+		}
+
+		comparison := TableStatsComparison{
+			TableName:       table,
+			DB1:             DB1Stats,
+			DB2:             DB2Stats,
+			RowDiffPercent:  diffPercent(DB1Stats.Rows(), DB2Stats.Rows()),
+			SizeDiffPercent: diffPercent(DB1Stats.TotalSize, DB2Stats.TotalSize),
+		}
+		comparison.Flagged = comparison.RowDiffPercent > threshold || comparison.SizeDiffPercent > threshold
+
+		comparisons = append(comparisons, comparison)
+	}
+
+	return comparisons, nil
+}
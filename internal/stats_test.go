@@ -0,0 +1,45 @@
+package internal
+
+import "testing"
+
+func TestDiffPercent(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b int64
+		want float64
+	}{
+		{name: "equal", a: 100, b: 100, want: 0},
+		{name: "both empty", a: 0, b: 0, want: 0},
+		{name: "first larger", a: 200, b: 150, want: 25},
+		{name: "second larger", a: 150, b: 200, want: 25},
+		{name: "one empty", a: 0, b: 10, want: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffPercent(tt.a, tt.b); got != tt.want {
+				t.Errorf("diffPercent(%d, %d) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableStatsRows(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats TableStats
+		want  int64
+	}{
+		{name: "estimate", stats: TableStats{EstimatedRows: 1200, ExactRows: -1}, want: 1200},
+		{name: "exact count", stats: TableStats{EstimatedRows: 1200, ExactRows: 1187}, want: 1187},
+		{name: "empty table", stats: TableStats{EstimatedRows: 1200, ExactRows: 0}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.Rows(); got != tt.want {
+				t.Errorf("Rows() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+type SyncOptions struct {
+	// Upsert writes inserts and updates as INSERT ... ON CONFLICT statements
+	Upsert    bool
+	BatchSize int
+	Data      DataOptions
+}
+
+type TableSyncSummary struct {
+	TableName string
+	Inserts   int
+	Updates   int
+	Deletes   int
+	// NotWritable counts the rows that only differ in generated or identity columns, which cannot be updated
+	NotWritable int
+}
+
+// syncTable holds what is needed to write the statements of a table.
+type syncTable struct {
+	name        string
+	primaryKey  []string
+	columns     []string
+	types       map[string]string
+	insertable  map[string]bool
+	updatable   map[string]bool
+	overriding  bool
+	differences []RowDifference
+}
+
+// GetColumnTypes returns the full type of every column of the table, including modifiers such as numeric(12,2).
+func GetColumnTypes(db *sql.DB, tableName string) (map[string]string, error) {
+	rows, err := db.Query(`SELECT
+		a.attname::text, format_type(a.atttypid, a.atttypmod)
+	FROM pg_attribute a
+	INNER JOIN pg_class c ON c.oid = a.attrelid
+	INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = 'public' AND c.relname = $1 AND a.attnum > 0 AND NOT a.attisdropped`, tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := map[string]string{}
+	for rows.Next() {
+		var name, typ string
+		if err = rows.Scan(&name, &typ); err != nil {
+			return nil, err
+		}
+
+		types[name] = typ
+	}
+
+	return types, rows.Err()
+}
+
+// GetForeignKeyDependencies returns the tables referenced by each table of the public schema.
+func GetForeignKeyDependencies(db *sql.DB) (map[string][]string, error) {
+	rows, err := db.Query(`SELECT DISTINCT
+		c.relname::text, r.relname::text
+	FROM pg_constraint con
+	INNER JOIN pg_class c ON c.oid = con.conrelid
+	INNER JOIN pg_class r ON r.oid = con.confrelid
+	INNER JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = 'public' AND con.contype = 'f'`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	dependencies := map[string][]string{}
+	for rows.Next() {
+		var table, referenced string
+		if err = rows.Scan(&table, &referenced); err != nil {
+			return nil, err
+		}
+
+		dependencies[table] = append(dependencies[table], referenced)
+	}
+
+	return dependencies, rows.Err()
+}
+
+// OrderTablesByDependencies sorts the tables so referenced tables come before the tables
+// referencing them. Tables in a reference cycle keep their alphabetical order.
+func OrderTablesByDependencies(tables []string, dependencies map[string][]string) []string {
+	selected := map[string]bool{}
+	for _, table := range tables {
+		selected[table] = true
+	}
+
+	sorted := append([]string{}, tables...)
+	sort.Strings(sorted)
+
+	ordered := []string{}
+	visited := map[string]bool{}
+	visiting := map[string]bool{}
+
+	var visit func(table string)
+	visit = func(table string) {
+		if visited[table] || visiting[table] {
+			return
+		}
+		visiting[table] = true
+
+		referenced := append([]string{}, dependencies[table]...)
+		sort.Strings(referenced)
+		for _, ref := range referenced {
+			if selected[ref] && ref != table {
+				visit(ref)
+			}
+		}
+
+		visiting[table] = false
+		visited[table] = true
+		ordered = append(ordered, table)
+	}
+
+	for _, table := range sorted {
+		visit(table)
+	}
+
+	return ordered
+}
+
+func (t syncTable) literal(column string, value sql.NullString) string {
+	if !value.Valid {
+		return "NULL"
+	}
+
+	// Values are read as text, casting them back restores every type through its text representation
+	return pq.QuoteLiteral(value.String) + "::" + t.types[column]
+}
+
+func (t syncTable) keyCondition(row Row) string {
+	conditions := []string{}
+	for _, col := range t.primaryKey {
+		conditions = append(conditions, fmt.Sprintf("%s = %s", pq.QuoteIdentifier(col), t.literal(col, row[col])))
+	}
+
+	return strings.Join(conditions, " AND ")
+}
+
+func (t syncTable) qualifiedName() string {
+	return qualifiedTable(t.name)
+}
+
+func (t syncTable) insertColumns() []string {
+	columns := []string{}
+	for _, col := range t.columns {
+		if t.insertable[col] {
+			columns = append(columns, col)
+		}
+	}
+
+	return columns
+}
+
+func (t syncTable) writeInserts(w io.Writer, rows []Row, batchSize int, upsert bool) error {
+	columns := t.insertColumns()
+
+	overriding := ""
+	if t.overriding {
+		overriding = " OVERRIDING SYSTEM VALUE"
+	}
+
+	conflict := ""
+	if upsert {
+		updates := []string{}
+		for _, col := range columns {
+			if t.updatable[col] && !contains(t.primaryKey, col) {
+				updates = append(updates, fmt.Sprintf("%[1]s = EXCLUDED.%[1]s", pq.QuoteIdentifier(col)))
+			}
+		}
+
+		conflict = fmt.Sprintf("\nON CONFLICT (%s) DO NOTHING", strings.Join(quoteIdentifiers(t.primaryKey), ", "))
+		if len(updates) > 0 {
+			conflict = fmt.Sprintf("\nON CONFLICT (%s) DO UPDATE SET %s", strings.Join(quoteIdentifiers(t.primaryKey), ", "), strings.Join(updates, ", "))
+		}
+	}
+
+	for start := 0; start < len(rows); start += batchSize {
+		end := min(start+batchSize, len(rows))
+
+		values := []string{}
+		for _, row := range rows[start:end] {
+			literals := []string{}
+			for _, col := range columns {
+				literals = append(literals, t.literal(col, row[col]))
+			}
+
+			values = append(values, "("+strings.Join(literals, ", ")+")")
+		}
+
+		_, err := fmt.Fprintf(w, "INSERT INTO %s (%s)%s VALUES\n%s%s;\n\n", t.qualifiedName(), strings.Join(quoteIdentifiers(columns), ", "), overriding, strings.Join(values, ",\n"), conflict)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writable reports whether any of the columns that differ in the row can be updated.
+func (t syncTable) writable(diff RowDifference) bool {
+	for _, col := range diff.Columns {
+		if t.updatable[col] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t syncTable) writeUpdates(w io.Writer, differences []RowDifference) error {
+	for _, diff := range differences {
+		assignments := []string{}
+		for _, col := range diff.Columns {
+			if t.updatable[col] {
+				assignments = append(assignments, fmt.Sprintf("%s = %s", pq.QuoteIdentifier(col), t.literal(col, diff.DB1[col])))
+			}
+		}
+
+		_, err := fmt.Fprintf(w, "UPDATE %s SET %s WHERE %s;\n", t.qualifiedName(), strings.Join(assignments, ", "), t.keyCondition(diff.DB2))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func (t syncTable) writeDeletes(w io.Writer, rows []Row, batchSize int) error {
+	for start := 0; start < len(rows); start += batchSize {
+		end := min(start+batchSize, len(rows))
+
+		keys := []string{}
+		for _, row := range rows[start:end] {
+			literals := []string{}
+			for _, col := range t.primaryKey {
+				literals = append(literals, t.literal(col, row[col]))
+			}
+
+			keys = append(keys, "("+strings.Join(literals, ", ")+")")
+		}
+
+		_, err := fmt.Fprintf(w, "DELETE FROM %s WHERE (%s) IN (\n%s\n);\n\n", t.qualifiedName(), strings.Join(quoteIdentifiers(t.primaryKey), ", "), strings.Join(keys, ",\n"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteSyncSQL compares the rows of the given tables by primary key and writes the statements that make
+// the second database (target) match the first one (source). Deletes are written children first and
+// inserts and updates parents first so foreign keys are respected.
+func WriteSyncSQL(w io.Writer, DB1 *sql.DB, DB2 *sql.DB, tables []string, options SyncOptions) ([]TableSyncSummary, error) {
+	if options.BatchSize < 1 {
+		options.BatchSize = 500
+	}
+	options.Data.MaxDifferences = 0
+	options.Data.SamplePercent, options.Data.SampleRows = 0, 0
+
+	DB1Tables, err := GetDBTableData(DB1)
+	if err != nil {
+		return nil, err
+	}
+
+	DB2Tables, err := GetDBTableData(DB2)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies, err := GetForeignKeyDependencies(DB2)
+	if err != nil {
+		return nil, err
+	}
+
+	syncTables := []syncTable{}
+	for _, name := range OrderTablesByDependencies(tables, dependencies) {
+		if _, ok := DB1Tables[name]; !ok {
+			return nil, fmt.Errorf("table %s does not exist in the source database", name)
+		}
+
+		if _, ok := DB2Tables[name]; !ok {
+			return nil, fmt.Errorf("table %s does not exist in the target database", name)
+		}
+
+		columns := CommonColumns(DB1Tables[name], DB2Tables[name])
+
+		comparison, err := CompareTableData(DB1, DB2, name, columns, options.Data)
+		if err != nil {
+			return nil, fmt.Errorf("comparing data of table %s: %w", name, err)
+		}
+
+		if comparison.Skipped != "" {
+			return nil, fmt.Errorf("cannot sync table %s: %s", name, comparison.Skipped)
+		}
+
+		types, err := GetColumnTypes(DB2, name)
+		if err != nil {
+			return nil, err
+		}
+
+		table := syncTable{
+			name:        name,
+			primaryKey:  comparison.PrimaryKey,
+			columns:     columns,
+			types:       types,
+			insertable:  map[string]bool{},
+			updatable:   map[string]bool{},
+			differences: comparison.Differences,
+		}
+
+		for _, col := range columns {
+			target := DB2Tables[name][col]
+
+			// Generated columns are computed by the target and cannot be written
+			table.insertable[col] = target.IsGenerated != "ALWAYS"
+			table.updatable[col] = target.IsGenerated != "ALWAYS" && target.IdentityGeneration != "ALWAYS"
+
+			if target.IdentityGeneration == "ALWAYS" {
+				table.overriding = true
+			}
+		}
+
+		syncTables = append(syncTables, table)
+	}
+
+	summaries := make([]TableSyncSummary, len(syncTables))
+	inserts := make([][]Row, len(syncTables))
+	updates := make([][]RowDifference, len(syncTables))
+	deletes := make([][]Row, len(syncTables))
+
+	for i, table := range syncTables {
+		summaries[i].TableName = table.name
+
+		for _, diff := range table.differences {
+			switch {
+			case diff.DB2 == nil:
+				inserts[i] = append(inserts[i], diff.DB1)
+				summaries[i].Inserts++
+			case diff.DB1 == nil:
+				deletes[i] = append(deletes[i], diff.DB2)
+				summaries[i].Deletes++
+			case !table.writable(diff):
+				summaries[i].NotWritable++
+			default:
+				updates[i] = append(updates[i], diff)
+				summaries[i].Updates++
+			}
+		}
+	}
+
+	if _, err = fmt.Fprintf(w, "-- Data synchronization script generated by go-dbcompare\n-- Tables: %s\n\nBEGIN;\n\nSET CONSTRAINTS ALL DEFERRED;\n\n", strings.Join(tables, ", ")); err != nil {
+		return nil, err
+	}
+
+	// Delete rows in children before their parents
+	for i := len(syncTables) - 1; i >= 0; i-- {
+		if len(deletes[i]) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "-- %s: delete %d rows\n", syncTables[i].name, len(deletes[i]))
+		if err = syncTables[i].writeDeletes(w, deletes[i], options.BatchSize); err != nil {
+			return nil, err
+		}
+	}
+
+	// Insert and update rows in parents before their children
+	for i, table := range syncTables {
+		if options.Upsert {
+			rows := append([]Row{}, inserts[i]...)
+			for _, diff := range updates[i] {
+				rows = append(rows, diff.DB1)
+			}
+
+			if len(rows) == 0 {
+				continue
+			}
+
+			fmt.Fprintf(w, "-- %s: upsert %d rows\n", table.name, len(rows))
+			if err = table.writeInserts(w, rows, options.BatchSize, true); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if len(inserts[i]) > 0 {
+			fmt.Fprintf(w, "-- %s: insert %d rows\n", table.name, len(inserts[i]))
+			if err = table.writeInserts(w, inserts[i], options.BatchSize, false); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(updates[i]) > 0 {
+			fmt.Fprintf(w, "-- %s: update %d rows\n", table.name, len(updates[i]))
+			if err = table.writeUpdates(w, updates[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, err = fmt.Fprintln(w, "COMMIT;"); err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
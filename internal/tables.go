@@ -2,43 +2,135 @@ package internal
 
 import (
 	"database/sql"
+	"fmt"
+	"sort"
+	"time"
 )
 
 type ColumnData struct {
-	TableName        string
-	ColumnName       string
-	DataType         NullString
-	ColumnDefault    NullString
-	IsNullable       string
-	CharMaxLen       NullInt
-	NumericPrecision NullInt
+	TableName            string
+	ColumnName           string
+	OrdinalPosition      int
+	DataType             NullString
+	ColumnDefault        NullString
+	IsNullable           string
+	CharMaxLen           NullInt
+	NumericPrecision     NullInt
+	NumericScale         NullInt
+	DatetimePrecision    NullInt
+	IntervalType         NullString
+	CollationName        NullString
+	ElementType          NullString
+	IsIdentity           NullString
+	IdentityGeneration   NullString
+	IsGenerated          NullString
+	GenerationExpression NullString
 }
 
-type Differences struct {
-	DB1 []ColumnData
-	DB2 []ColumnData
+type ColumnAttribute struct {
+	Name  string
+	Value string
+}
+
+// Attributes returns the comparable attributes of the column in display order.
+func (c ColumnData) Attributes() []ColumnAttribute {
+	return []ColumnAttribute{
+		{"Table Name", c.TableName},
+		{"Column Name", c.ColumnName},
+		{"Ordinal Position", fmt.Sprint(c.OrdinalPosition)},
+		{"Data Type", string(c.DataType)},
+		{"Column Default", string(c.ColumnDefault)},
+		{"Is Nullable", c.IsNullable},
+		{"Char Max Len", fmt.Sprint(c.CharMaxLen)},
+		{"Numeric Precision", fmt.Sprint(c.NumericPrecision)},
+		{"Numeric Scale", fmt.Sprint(c.NumericScale)},
+		{"Datetime Precision", fmt.Sprint(c.DatetimePrecision)},
+		{"Interval Type", string(c.IntervalType)},
+		{"Collation", string(c.CollationName)},
+		{"Array Element Type", string(c.ElementType)},
+		{"Is Identity", string(c.IsIdentity)},
+		{"Identity Generation", string(c.IdentityGeneration)},
+		{"Is Generated", string(c.IsGenerated)},
+		{"Generation Expression", string(c.GenerationExpression)},
+	}
+}
+
+// ComparedAttributes returns the attributes compared between both databases, without the table and
+// column names. The ordinal position is only compared when strictOrder is set.
+func (c ColumnData) ComparedAttributes(strictOrder bool) []ColumnAttribute {
+	attributes := []ColumnAttribute{}
+	for _, attr := range c.Attributes()[2:] {
+		if attr.Name == "Ordinal Position" && !strictOrder {
+			continue
+		}
+		attributes = append(attributes, attr)
+	}
+
+	return attributes
+}
+
+// ComparisonMetadata describes when the comparison ran, against which servers and with which options.
+type ComparisonMetadata struct {
+	StartedAt time.Time
+	Duration  time.Duration
+	DB1Host   string
+	DB2Host   string
+	Options   CompareOptions
 }
 
 type ComparisonResult struct {
+	Metadata ComparisonMetadata
+	// CommonTables are the tables present in both databases, sorted by name
+	CommonTables       []string
 	MissingTablesInDB1 []string
 	MissingTablesInDB2 []string
-	DifferencesResult  Differences
+	// Changes are the tables and columns that differ, ordered by table and column position
+	Changes            []Change
+	PolicyDifferences  []PolicyDifference
+	Partitions         []PartitionSummary
+	PartitionDiffs     []PartitionDifference
+	CommentDifferences []CommentDifference
+	TableStats         []TableStatsComparison
+	ProfileDifferences []ProfileDifference
+	Data               *DataComparison
+	Privileges         *PrivilegeComparison
+	Settings           *SettingsComparison
+	// DB1Tables and DB2Tables are the columns of every table in each database, as loaded for the comparison
+	DB1Tables map[string]map[string]ColumnData
+	DB2Tables map[string]map[string]ColumnData
 }
 
-func GetDBTableData(db *sql.DB) (map[string]map[string]ColumnData, error) {
+func GetDBTableData(db Querier) (map[string]map[string]ColumnData, error) {
 	rows, err := db.Query(`SELECT
-		c.table_name, c.column_name, c.data_type, c.column_default, c.is_nullable, c.character_maximum_length, c.numeric_precision
+		c.table_name, c.column_name,
+		-- Position among the existing columns, since dropped columns leave gaps in ordinal_position
+		row_number() OVER (PARTITION BY c.table_name ORDER BY c.ordinal_position),
+		c.data_type, c.column_default, c.is_nullable, c.character_maximum_length, c.numeric_precision,
+		c.numeric_scale, c.datetime_precision, c.interval_type, c.collation_name,
+		(
+			SELECT e.data_type FROM INFORMATION_SCHEMA.ELEMENT_TYPES e
+			WHERE e.object_schema = c.table_schema AND e.object_name = c.table_name
+				AND e.object_type = 'TABLE' AND e.collection_type_identifier = c.dtd_identifier
+		),
+		c.is_identity, c.identity_generation, c.is_generated, c.generation_expression
 	FROM
 		INFORMATION_SCHEMA.TABLES t
-	INNER JOIN INFORMATION_SCHEMA.COLUMNS c ON t.table_name = c.table_name
+	INNER JOIN INFORMATION_SCHEMA.COLUMNS c ON t.table_name = c.table_name AND t.table_schema = c.table_schema
 	WHERE
 		t.table_schema='public' AND t.table_type='BASE TABLE'
+		AND t.table_name NOT IN (
+			-- Partitions are summarized under their parent table
+			SELECT pc.relname FROM pg_class pc
+			INNER JOIN pg_namespace n ON n.oid = pc.relnamespace
+			WHERE n.nspname = 'public' AND pc.relispartition
+		)
 	ORDER BY
 		c.table_name ASC`)
 
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	// table > column > columnData
 	tables := map[string]map[string]ColumnData{}
@@ -46,7 +138,9 @@ func GetDBTableData(db *sql.DB) (map[string]map[string]ColumnData, error) {
 	for rows.Next() {
 		var col ColumnData
 
-		err = rows.Scan(&col.TableName, &col.ColumnName, &col.DataType, &col.ColumnDefault, &col.IsNullable, &col.CharMaxLen, &col.NumericPrecision)
+		err = rows.Scan(&col.TableName, &col.ColumnName, &col.OrdinalPosition, &col.DataType, &col.ColumnDefault, &col.IsNullable, &col.CharMaxLen, &col.NumericPrecision,
+			&col.NumericScale, &col.DatetimePrecision, &col.IntervalType, &col.CollationName, &col.ElementType,
+			&col.IsIdentity, &col.IdentityGeneration, &col.IsGenerated, &col.GenerationExpression)
 		if err != nil {
 			return nil, err
 		}
@@ -64,109 +158,196 @@ func GetDBTableData(db *sql.DB) (map[string]map[string]ColumnData, error) {
 	return tables, nil
 }
 
-func CompareTableCols(DB1Cols, DB2Cols map[string]ColumnData, differences Differences) Differences {
+// CompareTableCols returns the columns that differ between both tables. The column order is
+// only compared when options.StrictColumnOrder is set.
+func CompareTableCols(DB1Cols, DB2Cols map[string]ColumnData, options CompareOptions) []Change {
+	changes := []Change{}
+
 	for key, DB1Value := range DB1Cols {
 		DB2Value, ok := DB2Cols[key]
 		if !ok {
-			differences.DB1 = append(differences.DB1, DB1Value)
-			differences.DB2 = append(differences.DB2, ColumnData{
-				TableName:        DB1Value.TableName,
-				ColumnName:       "Null",
-				DataType:         "Null",
-				ColumnDefault:    "Null",
-				IsNullable:       "YES",
-				CharMaxLen:       0,
-				NumericPrecision: 0,
-			})
+			changes = append(changes, columnChange(nil, &DB1Value, options.StrictColumnOrder))
 			continue
 		}
 
-		DB1ColVals := []any{
-			DB1Value.ColumnName,
-			DB1Value.CharMaxLen,
-			DB1Value.ColumnDefault,
-			DB1Value.DataType,
-			DB1Value.IsNullable,
-			DB1Value.NumericPrecision,
-		}
-		DB2ColVals := []any{
-			DB2Value.ColumnName,
-			DB2Value.CharMaxLen,
-			DB2Value.ColumnDefault,
-			DB2Value.DataType,
-			DB2Value.IsNullable,
-			DB2Value.NumericPrecision,
-		}
-
-		for i, v := range DB1ColVals {
-			if v != DB2ColVals[i] {
-				differences.DB1 = append(differences.DB1, DB1Value)
-				differences.DB2 = append(differences.DB2, DB2Value)
-				break
-			}
+		change := columnChange(&DB2Value, &DB1Value, options.StrictColumnOrder)
+		if len(change.ChangedAttributes()) > 0 {
+			changes = append(changes, change)
 		}
-
 	}
 
 	// Second loop: Check keys in DB2Cols against DB1Cols
 	for key, DB2Value := range DB2Cols {
 		if _, ok := DB1Cols[key]; !ok {
-			differences.DB1 = append(differences.DB1, ColumnData{
-				TableName:        DB2Value.TableName,
-				ColumnName:       "Null",
-				DataType:         "Null",
-				ColumnDefault:    "Null",
-				IsNullable:       "YES",
-				CharMaxLen:       0,
-				NumericPrecision: 0,
-			})
-			differences.DB2 = append(differences.DB2, DB2Value)
+			changes = append(changes, columnChange(&DB2Value, nil, options.StrictColumnOrder))
 		}
 	}
 
-	return differences
-}
+	sortChanges(changes)
 
-func CompareDatabase(DB1 *sql.DB, DB2 *sql.DB) (ComparisonResult, error) {
-	differences := Differences{
-		DB1: []ColumnData{},
-		DB2: []ColumnData{},
-	}
+	return changes
+}
 
+// CompareTables compares the columns of the tables present in both databases and lists the missing
+// tables. It also returns the names of the tables present in both databases.
+func CompareTables(Database1TableData, Database2TableData map[string]map[string]ColumnData, options CompareOptions) (ComparisonResult, []string) {
 	comparisonResult := ComparisonResult{
 		MissingTablesInDB1: []string{},
 		MissingTablesInDB2: []string{},
+		Changes:            []Change{},
 	}
 
-	Database1TableData, err := GetDBTableData(DB1)
-	if err != nil {
-		return comparisonResult, err
-	}
-
-	Database2TableData, err := GetDBTableData(DB2)
-	if err != nil {
-		return comparisonResult, err
-	}
-
+	commonTables := []string{}
 	for DB1Key, DB1Value := range Database1TableData {
 		DB2Value, ok := Database2TableData[DB1Key]
 		if !ok {
 			// Table not found in database 2
 			comparisonResult.MissingTablesInDB2 = append(comparisonResult.MissingTablesInDB2, DB1Key)
+			comparisonResult.Changes = append(comparisonResult.Changes, tableChange(DB1Key, ChangeAdded))
 			continue
 		}
+		commonTables = append(commonTables, DB1Key)
 
-		differences = CompareTableCols(DB1Value, DB2Value, differences)
+		comparisonResult.Changes = append(comparisonResult.Changes, CompareTableCols(DB1Value, DB2Value, options)...)
 	}
 
 	// Find all missing tables in database 1
 	for DB2Key := range Database2TableData {
 		if _, ok := Database1TableData[DB2Key]; !ok {
 			comparisonResult.MissingTablesInDB1 = append(comparisonResult.MissingTablesInDB1, DB2Key)
+			comparisonResult.Changes = append(comparisonResult.Changes, tableChange(DB2Key, ChangeRemoved))
+		}
+	}
+
+	sort.Strings(commonTables)
+	sort.Strings(comparisonResult.MissingTablesInDB1)
+	sort.Strings(comparisonResult.MissingTablesInDB2)
+	sortChanges(comparisonResult.Changes)
+	comparisonResult.CommonTables = commonTables
+
+	return comparisonResult, commonTables
+}
+
+// ColumnChanges returns the changes of the columns of the tables present in both databases.
+func (c ComparisonResult) ColumnChanges() []Change {
+	changes := []Change{}
+	for _, change := range c.Changes {
+		if change.Kind == ObjectColumn {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+// serverAddress returns the address, port and database the connection is using.
+func serverAddress(db *sql.DB) (string, error) {
+	var address string
+	err := db.QueryRow(`SELECT coalesce(host(inet_server_addr()), 'local socket') || ':' || current_setting('port') || '/' || current_database()`).Scan(&address)
+
+	return address, err
+}
+
+func CompareDatabase(DB1 *sql.DB, DB2 *sql.DB, options CompareOptions) (ComparisonResult, error) {
+	startedAt := time.Now()
+
+	Database1TableData, err := GetDBTableData(DB1)
+	if err != nil {
+		return ComparisonResult{}, err
+	}
+
+	Database2TableData, err := GetDBTableData(DB2)
+	if err != nil {
+		return ComparisonResult{}, err
+	}
+
+	comparisonResult, commonTables := CompareTables(Database1TableData, Database2TableData, options)
+	comparisonResult.Metadata = ComparisonMetadata{StartedAt: startedAt, Options: options}
+	comparisonResult.DB1Tables, comparisonResult.DB2Tables = Database1TableData, Database2TableData
+
+	comparisonResult.Metadata.DB1Host, err = serverAddress(DB1)
+	if err != nil {
+		return comparisonResult, err
+	}
+
+	comparisonResult.Metadata.DB2Host, err = serverAddress(DB2)
+	if err != nil {
+		return comparisonResult, err
+	}
+
+	comparisonResult.PolicyDifferences, err = ComparePolicies(DB1, DB2)
+	if err != nil {
+		return comparisonResult, err
+	}
+
+	DB1Partitions, err := GetDBPartitionedTables(DB1)
+	if err != nil {
+		return comparisonResult, err
+	}
+
+	DB2Partitions, err := GetDBPartitionedTables(DB2)
+	if err != nil {
+		return comparisonResult, err
+	}
+
+	comparisonResult.Partitions, comparisonResult.PartitionDiffs = ComparePartitions(DB1Partitions, DB2Partitions, options.StrictPartitionBounds)
+
+	if options.Comments {
+		DB1Comments, err := GetDBComments(DB1)
+		if err != nil {
+			return comparisonResult, err
+		}
+
+		DB2Comments, err := GetDBComments(DB2)
+		if err != nil {
+			return comparisonResult, err
 		}
+
+		comparisonResult.CommentDifferences = CompareComments(DB1Comments, DB2Comments)
+	}
+
+	if options.Stats {
+		comparisonResult.TableStats, err = CompareTableStats(DB1, DB2, commonTables, options.ExactCounts, options.StatsThreshold)
+		if err != nil {
+			return comparisonResult, err
+		}
+	}
+
+	if options.Profile {
+		comparisonResult.ProfileDifferences, err = CompareProfiles(DB1, DB2, Database1TableData, Database2TableData, commonTables, options.ProfileOptions)
+		if err != nil {
+			return comparisonResult, err
+		}
+	}
+
+	if options.Data {
+		data, err := CompareData(DB1, DB2, Database1TableData, Database2TableData, commonTables, options.DataOptions)
+		if err != nil {
+			return comparisonResult, err
+		}
+
+		comparisonResult.Data = &data
+	}
+
+	if options.Privileges {
+		privileges, err := ComparePrivilegeData(DB1, DB2)
+		if err != nil {
+			return comparisonResult, err
+		}
+
+		comparisonResult.Privileges = &privileges
+	}
+
+	if options.Settings {
+		settings, err := CompareSettings(DB1, DB2, options.IgnoredSettings)
+		if err != nil {
+			return comparisonResult, err
+		}
+
+		comparisonResult.Settings = &settings
 	}
 
-	comparisonResult.DifferencesResult = differences
+	comparisonResult.Metadata.Duration = time.Since(startedAt)
 
 	return comparisonResult, nil
 }
@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompareTableCols(t *testing.T) {
+	column := func(name string, position int, change func(*ColumnData)) ColumnData {
+		col := ColumnData{
+			TableName: "orders", ColumnName: name, OrdinalPosition: position, DataType: "integer", ColumnDefault: "Null",
+			IsNullable: "YES", NumericPrecision: 32, IntervalType: "Null", CollationName: "Null", ElementType: "Null",
+			IsIdentity: "NO", IdentityGeneration: "Null", IsGenerated: "NEVER", GenerationExpression: "Null",
+		}
+		if change != nil {
+			change(&col)
+		}
+		return col
+	}
+	columns := func(cols ...ColumnData) map[string]ColumnData {
+		m := map[string]ColumnData{}
+		for _, col := range cols {
+			m[col.ColumnName] = col
+		}
+		return m
+	}
+
+	tests := []struct {
+		name    string
+		DB1     map[string]ColumnData
+		DB2     map[string]ColumnData
+		options CompareOptions
+		want    []string
+	}{
+		{
+			name: "same columns",
+			DB1:  columns(column("id", 1, nil), column("total", 2, nil)),
+			DB2:  columns(column("id", 1, nil), column("total", 2, nil)),
+			want: []string{},
+		},
+		{
+			name: "added and removed columns",
+			DB1:  columns(column("id", 1, nil), column("total", 2, nil)),
+			DB2:  columns(column("id", 1, nil), column("amount", 2, nil)),
+			want: []string{"amount removed", "total added"},
+		},
+		{
+			name: "reordered columns without strict order",
+			DB1:  columns(column("id", 1, nil), column("total", 2, nil)),
+			DB2:  columns(column("id", 2, nil), column("total", 1, nil)),
+			want: []string{},
+		},
+		{
+			name:    "reordered columns with strict order",
+			DB1:     columns(column("id", 1, nil), column("total", 2, nil)),
+			DB2:     columns(column("id", 2, nil), column("total", 1, nil)),
+			options: CompareOptions{StrictColumnOrder: true},
+			want:    []string{"id modified Ordinal Position", "total modified Ordinal Position"},
+		},
+		{
+			name: "identity column",
+			DB1: columns(column("id", 1, func(c *ColumnData) {
+				c.IsIdentity, c.IdentityGeneration, c.IsNullable = "YES", "ALWAYS", "NO"
+			})),
+			DB2: columns(column("id", 1, func(c *ColumnData) {
+				c.IsIdentity, c.IdentityGeneration, c.IsNullable = "YES", "BY DEFAULT", "NO"
+			})),
+			want: []string{"id modified Identity Generation"},
+		},
+		{
+			name: "generated column",
+			DB1: columns(column("total", 1, func(c *ColumnData) {
+				c.IsGenerated, c.GenerationExpression = "ALWAYS", "(price * quantity)"
+			})),
+			DB2:  columns(column("total", 1, nil)),
+			want: []string{"total modified Is Generated, Generation Expression"},
+		},
+		{
+			name: "collation",
+			DB1: columns(column("name", 1, func(c *ColumnData) {
+				c.DataType, c.NumericPrecision, c.CollationName = "text", 0, "C"
+			})),
+			DB2: columns(column("name", 1, func(c *ColumnData) {
+				c.DataType, c.NumericPrecision, c.CollationName = "text", 0, "en_US"
+			})),
+			want: []string{"name modified Collation"},
+		},
+		{
+			name: "precision and scale",
+			DB1: columns(column("total", 1, func(c *ColumnData) {
+				c.DataType, c.NumericPrecision, c.NumericScale = "numeric", 12, 2
+			})),
+			DB2: columns(column("total", 1, func(c *ColumnData) {
+				c.DataType, c.NumericPrecision, c.NumericScale = "numeric", 10, 4
+			})),
+			want: []string{"total modified Numeric Precision, Numeric Scale"},
+		},
+		{
+			name: "datetime precision",
+			DB1: columns(column("created_at", 1, func(c *ColumnData) {
+				c.DataType, c.NumericPrecision, c.DatetimePrecision = "timestamp without time zone", 0, 3
+			})),
+			DB2: columns(column("created_at", 1, func(c *ColumnData) {
+				c.DataType, c.NumericPrecision, c.DatetimePrecision = "timestamp without time zone", 0, 6
+			})),
+			want: []string{"created_at modified Datetime Precision"},
+		},
+		{
+			name: "interval fields",
+			DB1: columns(column("duration", 1, func(c *ColumnData) {
+				c.DataType, c.NumericPrecision, c.IntervalType = "interval", 0, "DAY"
+			})),
+			DB2: columns(column("duration", 1, func(c *ColumnData) {
+				c.DataType, c.NumericPrecision = "interval", 0
+			})),
+			want: []string{"duration modified Interval Type"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, change := range CompareTableCols(tt.DB1, tt.DB2, tt.options) {
+				summary := fmt.Sprintf("%s %s", change.Column, change.Type)
+				if change.Type == ChangeModified {
+					names := []string{}
+					for _, attr := range change.ChangedAttributes() {
+						names = append(names, attr.Name)
+					}
+					summary += " " + strings.Join(names, ", ")
+				}
+				got = append(got, summary)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareTableCols() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
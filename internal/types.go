@@ -1,6 +1,24 @@
 package internal
 
-import "fmt"
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Schema is the schema compared in both databases.
+const Schema = "public"
+
+// QualifiedName joins the names with the schema, such as schema.table or schema.table.column.
+func QualifiedName(names ...string) string {
+	return strings.Join(append([]string{Schema}, names...), ".")
+}
+
+// Querier is implemented by both *sql.DB and *sql.Tx, so introspection can also run inside a transaction.
+type Querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
 
 type NullString string
 
@@ -14,6 +32,9 @@ func (ns *NullString) Scan(value any) error {
 	case string:
 		*ns = NullString(v)
 		return nil
+	case []byte:
+		*ns = NullString(v)
+		return nil
 	default:
 		return fmt.Errorf("unsupported scan type %T", value)
 	}
@@ -50,6 +71,32 @@ type DBConfig struct {
 }
 
 type Configuration struct {
-	DB1 DBConfig `json:"database1"`
-	DB2 DBConfig `json:"database2"`
+	DB1         DBConfig    `json:"database1"`
+	DB2         DBConfig    `json:"database2"`
+	DataFilters DataFilters `json:"data_filters,omitempty"`
+	Comparators Comparators `json:"comparators,omitempty"`
+}
+
+type CompareOptions struct {
+	Privileges            bool
+	StrictPartitionBounds bool
+	StrictColumnOrder     bool
+	Comments              bool
+	Settings              bool
+	IgnoredSettings       []string
+	Stats                 bool
+	ExactCounts           bool
+	StatsThreshold        float64
+	Profile               bool
+	ProfileOptions        ProfileOptions
+	Data                  bool
+	DataOptions           DataOptions
 }
+
+type Severity string
+
+const (
+	SeverityLow    Severity = "Low"
+	SeverityMedium Severity = "Medium"
+	SeverityHigh   Severity = "High"
+)